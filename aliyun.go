@@ -9,7 +9,10 @@ import (
 	"github.com/denverdino/aliyungo/cs"
 
 	"github.com/aliyun/alibaba-cloud-sdk-go/services/alidns"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/dds"
 	"github.com/aliyun/alibaba-cloud-sdk-go/services/ecs"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/pvtz"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/r-kvstore"
 	"github.com/aliyun/alibaba-cloud-sdk-go/services/rds"
 	"github.com/aliyun/alibaba-cloud-sdk-go/services/slb"
 	"github.com/aliyun/alibaba-cloud-sdk-go/services/vpc"
@@ -35,13 +38,16 @@ type Aliyun struct {
 	Code            string
 	// ZoneId          string
 
-	vpcClient *vpc.Client
-	ecsClient *ecs.Client
-	ossClient *oss.Client
-	rdsClient *rds.Client
-	csClient  *cs.Client
-	slbClient *slb.Client
-	dnsClient *alidns.Client
+	vpcClient   *vpc.Client
+	ecsClient   *ecs.Client
+	ossClient   *oss.Client
+	rdsClient   *rds.Client
+	csClient    *cs.Client
+	slbClient   *slb.Client
+	dnsClient   *alidns.Client
+	pvtzClient  *pvtz.Client
+	redisClient *r_kvstore.Client
+	ddsClient   *dds.Client
 }
 
 func NewAliyun(ctx context.Context, conf config.Configuration) *Aliyun {
@@ -165,6 +171,42 @@ func (p *Aliyun) DNSClient() *alidns.Client {
 	return p.dnsClient
 }
 
+func (p *Aliyun) PVTZClient() *pvtz.Client {
+	if p.pvtzClient == nil {
+		var err error
+		p.pvtzClient, err = pvtz.NewClientWithAccessKey(p.Region, p.AccessKeyId, p.AccessKeySecret)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	return p.pvtzClient
+}
+
+func (p *Aliyun) RedisClient() *r_kvstore.Client {
+	if p.redisClient == nil {
+		var err error
+		p.redisClient, err = r_kvstore.NewClientWithAccessKey(p.Region, p.AccessKeyId, p.AccessKeySecret)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	return p.redisClient
+}
+
+func (p *Aliyun) MongoDBClient() *dds.Client {
+	if p.ddsClient == nil {
+		var err error
+		p.ddsClient, err = dds.NewClientWithAccessKey(p.Region, p.AccessKeyId, p.AccessKeySecret)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	return p.ddsClient
+}
+
 func (p *Aliyun) signWithCode(str string) string {
 	return fmt.Sprintf("%s [%s]", str, p.Code)
 }
@@ -173,6 +215,21 @@ func (p *Aliyun) isSignd(str string) bool {
 	return strings.Contains(str, fmt.Sprintf("[%s]", p.Code))
 }
 
+// codeTags returns a copy of tags with the creator and code tags of the resources created by this code
+func (p *Aliyun) codeTags(tags map[string]string) map[string]string {
+
+	ret := make(map[string]string, len(tags)+2)
+
+	for k, v := range tags {
+		ret[k] = v
+	}
+
+	ret["creator"] = "go-flow"
+	ret["code"] = p.Code
+
+	return ret
+}
+
 func IsAliErrCode(err error, code string) bool {
 
 	switch v := err.(type) {
@@ -193,6 +250,15 @@ func IsAliErrCode(err error, code string) bool {
 	return false
 }
 
+func stringInSlice(str string, strs []string) bool {
+	for _, s := range strs {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
+
 func setENV(key, value string) error {
 	key = strings.Replace(key, "-", "_", -1)
 	key = strings.Replace(key, ".", "_", -1)
@@ -1,7 +1,10 @@
 package aliyun
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/aliyun/alibaba-cloud-sdk-go/services/vpc"
 
@@ -356,3 +359,47 @@ func (p *Aliyun) CreateDockerClusterVolumnArgs() (createArgs []*DockerClusterVol
 
 	return
 }
+
+func (p *Aliyun) WaitForDockerClusters(ctx context.Context, status cs.ClusterState, timeout int) (err error) {
+
+	clusters, err := p.CSClient().DescribeClusters("")
+	if err != nil {
+		return
+	}
+
+	ready := []string{string(status)}
+	terminal := []string{string(cs.Failed), string(cs.Deleting), string(cs.Deleted)}
+
+	if status == cs.Deleted {
+		ready = append(ready, WaitNotFound)
+		terminal = []string{string(cs.DeleteFailed)}
+	}
+
+	var waits []func(ctx context.Context) error
+
+	for _, cluster := range clusters {
+
+		clusterId := cluster.ClusterID
+
+		waiter := p.newWaiter(fmt.Sprintf("cluster '%s' to %s", cluster.Name, status), time.Duration(timeout)*time.Second, ready, terminal...)
+		waiter.Fields["DOCKER-CLUSTER-ID"] = cluster.ClusterID
+		waiter.Fields["DOCKER-CLUSTER-NAME"] = cluster.Name
+
+		waits = append(waits, func(ctx context.Context) error {
+			return waiter.Wait(ctx, func() (string, error) {
+				c, e := p.CSClient().DescribeCluster(clusterId)
+				if e != nil {
+					if strings.Contains(e.Error(), "ErrorClusterNotFound") {
+						return WaitNotFound, nil
+					}
+					return "", e
+				}
+				return string(c.State), nil
+			})
+		})
+	}
+
+	err = p.waitAll(ctx, waits...)
+
+	return
+}
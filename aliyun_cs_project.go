@@ -3,6 +3,7 @@ package aliyun
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"crypto/md5"
 	"crypto/sha256"
 	"fmt"
@@ -12,7 +13,6 @@ import (
 	"os"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/chr4/pwgen"
@@ -35,21 +35,22 @@ type DockerProjectCreationArgs struct {
 
 	WaitProjects []string
 	CreationArgs *cs.ProjectCreationArgs
+
+	waiter          *Waiter
+	waitConcurrency int
 }
 
 func (p *DockerProjectCreationArgs) Create() error {
 	return p.Client.CreateProject(p.CreationArgs)
 }
 
-func (p *DockerProjectCreationArgs) Wait() error {
+func (p *DockerProjectCreationArgs) Wait(ctx context.Context) error {
 
 	if len(p.WaitProjects) == 0 {
 		return nil
 	}
 
-	wg := &sync.WaitGroup{}
-
-	errChan := make(chan error, 1)
+	var waits []func(ctx context.Context) error
 
 	for _, projName := range p.WaitProjects {
 
@@ -57,42 +58,28 @@ func (p *DockerProjectCreationArgs) Wait() error {
 			continue
 		}
 
-		wg.Add(1)
+		name := projName
 
-		go func(name string) {
+		waiter := *p.waiter
+		waiter.Name = fmt.Sprintf("project '%s' running", name)
 
-			defer wg.Done()
+		waiter.Fields = logrus.Fields{"DOCKER-PROJECT-NAME": name}
+		for k, v := range p.waiter.Fields {
+			waiter.Fields[k] = v
+		}
 
-			for {
+		waits = append(waits, func(ctx context.Context) error {
+			return waiter.Wait(ctx, func() (string, error) {
 				proj, err := p.Client.GetProject(name)
 				if err != nil {
-					select {
-					case errChan <- err:
-					default:
-					}
-					return
+					return "", err
 				}
-				if cs.ClusterState(proj.CurrentState) == cs.Running {
-					return
-				}
-
-				time.Sleep(time.Second * 5)
-			}
-
-		}(projName)
+				return proj.CurrentState, nil
+			})
+		})
 	}
 
-	wg.Wait()
-
-	var err error
-
-	select {
-	case err = <-errChan:
-	default:
-	}
-
-	return err
-
+	return WaitAll(ctx, p.waitConcurrency, waits...)
 }
 
 type DockerProject struct {
@@ -249,9 +236,14 @@ func (p *Aliyun) CreateDockerProjectArgs() (createArgs []*DockerProjectCreationA
 
 			waitProjects := projectConf.GetStringList("wait.projects")
 
+			waiter := p.newWaiter("project", time.Duration(projectConf.GetInt32("wait.timeout", 600))*time.Second, []string{string(cs.Running)}, string(cs.Failed))
+			waiter.Fields["DOCKER-CLUSTER-NAME"] = clusterName
+
 			arg := &DockerProjectCreationArgs{
 				WaitProjects:        waitProjects,
 				DockerProjectClient: cli,
+				waiter:              waiter,
+				waitConcurrency:     int(p.Config.GetInt32("aliyun.wait.concurrency", 0)),
 				CreationArgs: &cs.ProjectCreationArgs{
 					Name:        needCreateProjectName,
 					Description: projectConf.GetString("description"),
@@ -1,6 +1,8 @@
 package aliyun
 
 import (
+	"fmt"
+
 	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
 	"github.com/aliyun/alibaba-cloud-sdk-go/services/alidns"
 
@@ -15,6 +17,8 @@ func (p *Aliyun) AddDomainRecord() (err error) {
 		return
 	}
 
+	valueCache := &dnsValueCache{}
+
 	for _, dnsConfName := range dnsListConf.Keys() {
 
 		dnsConf := dnsListConf.GetConfig(dnsConfName)
@@ -28,32 +32,122 @@ func (p *Aliyun) AddDomainRecord() (err error) {
 		req.DomainName = dnsConf.GetString("domain-name")
 		req.RR = dnsConf.GetString("rr")
 		req.Type = dnsConf.GetString("type")
-		req.Value = dnsConf.GetString("value")
 		req.TTL = requests.NewInteger(int(dnsConf.GetInt32("ttl", 600)))
 		req.Priority = requests.NewInteger(int(dnsConf.GetInt32("priority", 10)))
 		req.Line = dnsConf.GetString("line", "default")
 
-		_, err = p.DNSClient().AddDomainRecord(req)
-
-		if IsAliErrCode(err, "DomainRecordDuplicate") {
-
-			logrus.WithField("DOMAIN", req.DomainName).
-				WithField("RR", req.RR).
-				WithField("TYPE", req.Type).
-				WithField("VALUE", req.Value).Warnln("Domain record already exist")
-
-			err = nil
-			continue
+		req.Value, err = p.resolveDNSValue(valueCache, dnsConf.GetString("value"))
+		if err != nil {
+			return
 		}
 
+		err = p.addDomainRecord(req)
 		if err != nil {
 			return
 		}
+	}
+
+	return
+}
+
+func (p *Aliyun) addDomainRecord(req *alidns.AddDomainRecordRequest) (err error) {
+
+	_, err = p.DNSClient().AddDomainRecord(req)
+
+	if IsAliErrCode(err, "DomainRecordDuplicate") {
 
 		logrus.WithField("DOMAIN", req.DomainName).
 			WithField("RR", req.RR).
 			WithField("TYPE", req.Type).
-			WithField("VALUE", req.Value).Infoln("Domain record created")
+			WithField("VALUE", req.Value).Warnln("Domain record already exist")
+
+		err = nil
+		return
+	}
+
+	if err != nil {
+		return
+	}
+
+	logrus.WithField("DOMAIN", req.DomainName).
+		WithField("RR", req.RR).
+		WithField("TYPE", req.Type).
+		WithField("VALUE", req.Value).Infoln("Domain record created")
+
+	return
+}
+
+type DomainRecordFilter struct {
+	DomainName string
+	RR         string
+	Type       string
+	Line       string
+	Value      string
+}
+
+func (p *Aliyun) listDomainRecords(domainName, rrKeyWord, typeKeyWord string) (records []alidns.Record, err error) {
+
+	pageNumber := 1
+	pageSize := 500
+
+	for {
+		describeReq := alidns.CreateDescribeDomainRecordsRequest()
+
+		describeReq.DomainName = domainName
+		describeReq.RRKeyWord = rrKeyWord
+		describeReq.TypeKeyWord = typeKeyWord
+		describeReq.PageNumber = requests.NewInteger(pageNumber)
+		describeReq.PageSize = requests.NewInteger(pageSize)
+
+		var describeResp *alidns.DescribeDomainRecordsResponse
+
+		describeResp, err = p.DNSClient().DescribeDomainRecords(describeReq)
+
+		if err != nil {
+			return
+		}
+
+		records = append(records, describeResp.DomainRecords.Record...)
+
+		if len(describeResp.DomainRecords.Record) < pageSize ||
+			int64(len(records)) >= int64(describeResp.TotalCount) {
+			break
+		}
+
+		pageNumber++
+	}
+
+	return
+}
+
+func (p *Aliyun) FindDomainRecords(filter DomainRecordFilter) (records []alidns.Record, err error) {
+
+	if len(filter.DomainName) == 0 || len(filter.RR) == 0 || len(filter.Type) == 0 {
+		err = fmt.Errorf("domain-name, rr and type are required to find domain records")
+		return
+	}
+
+	if len(filter.Line) == 0 {
+		filter.Line = "default"
+	}
+
+	allRecords, err := p.listDomainRecords(filter.DomainName, filter.RR, filter.Type)
+	if err != nil {
+		return
+	}
+
+	for _, record := range allRecords {
+		if record.RR != filter.RR ||
+			record.Type != filter.Type ||
+			record.Line != filter.Line {
+			continue
+		}
+
+		if len(filter.Value) > 0 && record.Value != filter.Value {
+			continue
+		}
+
+		records = append(records, record)
 	}
 
 	return
@@ -67,6 +161,8 @@ func (p *Aliyun) UpdateDomainRecord() (err error) {
 		return
 	}
 
+	valueCache := &dnsValueCache{}
+
 	for _, dnsConfName := range dnsListConf.Keys() {
 
 		dnsConf := dnsListConf.GetConfig(dnsConfName)
@@ -75,39 +171,55 @@ func (p *Aliyun) UpdateDomainRecord() (err error) {
 			return
 		}
 
-		describeReq := alidns.CreateDescribeDomainRecordsRequest()
+		req := alidns.CreateUpdateDomainRecordRequest()
+
+		req.RR = dnsConf.GetString("rr")
+		req.Type = dnsConf.GetString("type")
+		req.TTL = requests.NewInteger(int(dnsConf.GetInt32("ttl", 600)))
+		req.Priority = requests.NewInteger(int(dnsConf.GetInt32("priority", 10)))
+		req.Line = dnsConf.GetString("line", "default")
 
-		describeReq.DomainName = dnsConf.GetString("domain-name")
+		req.Value, err = p.resolveDNSValue(valueCache, dnsConf.GetString("value"))
+		if err != nil {
+			return
+		}
 
-		var describeResp *alidns.DescribeDomainRecordsResponse
+		filter := DomainRecordFilter{
+			DomainName: dnsConf.GetString("domain-name"),
+			RR:         req.RR,
+			Type:       req.Type,
+			Line:       req.Line,
+			Value:      dnsConf.GetString("match-value"),
+		}
 
-		describeResp, err = p.DNSClient().DescribeDomainRecords(describeReq)
+		var records []alidns.Record
+		records, err = p.FindDomainRecords(filter)
 
 		if err != nil {
 			return
 		}
 
-		req := alidns.CreateUpdateDomainRecordRequest()
-
-		req.RR = dnsConf.GetString("rr")
-		req.Type = dnsConf.GetString("type")
-		req.Value = dnsConf.GetString("value")
-		req.TTL = requests.NewInteger(int(dnsConf.GetInt32("ttl", 600)))
-		req.Priority = requests.NewInteger(int(dnsConf.GetInt32("priority", 10)))
-		req.Line = dnsConf.GetString("line", "default")
+		if len(records) == 0 {
+			err = fmt.Errorf("domain record of %s not found, domain: %s, rr: %s, type: %s, line: %s", dnsConfName, filter.DomainName, filter.RR, filter.Type, filter.Line)
+			return
+		}
 
-		if len(describeResp.DomainRecords.Record) == 0 {
-			continue
+		if len(records) > 1 {
+			err = fmt.Errorf("more than one domain record matched for %s, domain: %s, rr: %s, type: %s, line: %s, please set match-value", dnsConfName, filter.DomainName, filter.RR, filter.Type, filter.Line)
+			return
 		}
 
-		record := describeResp.DomainRecords.Record[0]
+		record := records[0]
+
+		if record.Value == req.Value &&
+			requests.NewInteger(int(record.TTL)) == req.TTL &&
+			(record.Type != "MX" || requests.NewInteger(int(record.Priority)) == req.Priority) {
+
+			logrus.WithField("DOMAIN", filter.DomainName).
+				WithField("RR", req.RR).
+				WithField("TYPE", req.Type).
+				WithField("VALUE", req.Value).Debugln("Domain record not changed")
 
-		if record.RR == req.RR &&
-			record.Type == req.Type &&
-			record.Value == req.Value &&
-			requests.Integer(record.TTL) == req.TTL &&
-			requests.Integer(record.Priority) == req.Priority &&
-			record.Line == req.Line {
 			continue
 		}
 
@@ -118,6 +230,11 @@ func (p *Aliyun) UpdateDomainRecord() (err error) {
 		if err != nil {
 			return
 		}
+
+		logrus.WithField("DOMAIN", filter.DomainName).
+			WithField("RR", req.RR).
+			WithField("TYPE", req.Type).
+			WithField("VALUE", req.Value).Infoln("Domain record updated")
 	}
 
 	return
@@ -131,6 +248,8 @@ func (p *Aliyun) DeleteDomainRecord() (err error) {
 		return
 	}
 
+	valueCache := &dnsValueCache{}
+
 	for _, dnsConfName := range dnsListConf.Keys() {
 
 		dnsConf := dnsListConf.GetConfig(dnsConfName)
@@ -139,31 +258,48 @@ func (p *Aliyun) DeleteDomainRecord() (err error) {
 			return
 		}
 
-		describeReq := alidns.CreateDescribeDomainRecordsRequest()
-
-		describeReq.DomainName = dnsConf.GetString("domain-name")
+		filter := DomainRecordFilter{
+			DomainName: dnsConf.GetString("domain-name"),
+			RR:         dnsConf.GetString("rr"),
+			Type:       dnsConf.GetString("type"),
+			Line:       dnsConf.GetString("line", "default"),
+		}
 
-		var describeResp *alidns.DescribeDomainRecordsResponse
+		filter.Value, err = p.resolveDNSValue(valueCache, dnsConf.GetString("value"))
+		if err != nil {
+			return
+		}
 
-		describeResp, err = p.DNSClient().DescribeDomainRecords(describeReq)
+		var records []alidns.Record
+		records, err = p.FindDomainRecords(filter)
 
 		if err != nil {
 			return
 		}
 
-		if len(describeResp.DomainRecords.Record) == 0 {
+		if len(records) == 0 {
+			logrus.WithField("DOMAIN", filter.DomainName).
+				WithField("RR", filter.RR).
+				WithField("TYPE", filter.Type).
+				WithField("VALUE", filter.Value).Warnln("Domain record not exist, ignore to delete")
 			continue
 		}
 
-		record := describeResp.DomainRecords.Record[0]
+		for _, record := range records {
 
-		req := alidns.CreateDeleteDomainRecordRequest()
-		req.RecordId = record.RecordId
+			req := alidns.CreateDeleteDomainRecordRequest()
+			req.RecordId = record.RecordId
 
-		_, err = p.DNSClient().DeleteDomainRecord(req)
+			_, err = p.DNSClient().DeleteDomainRecord(req)
 
-		if err != nil {
-			return
+			if err != nil {
+				return
+			}
+
+			logrus.WithField("DOMAIN", filter.DomainName).
+				WithField("RR", record.RR).
+				WithField("TYPE", record.Type).
+				WithField("VALUE", record.Value).Infoln("Domain record deleted")
 		}
 	}
 
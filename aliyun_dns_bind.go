@@ -0,0 +1,568 @@
+package aliyun
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/alidns"
+
+	"github.com/sirupsen/logrus"
+)
+
+var bindSupportedTypes = map[string]bool{
+	"A":     true,
+	"AAAA":  true,
+	"CNAME": true,
+	"MX":    true,
+	"TXT":   true,
+	"NS":    true,
+	"SRV":   true,
+	"CAA":   true,
+}
+
+func (p *Aliyun) ExportDNSZones() (err error) {
+
+	exportsConf := p.Config.GetConfig("aliyun.dns-zone.export")
+
+	if exportsConf.IsEmpty() {
+		return
+	}
+
+	for _, name := range exportsConf.Keys() {
+
+		exportConf := exportsConf.GetConfig(name)
+
+		domainName := exportConf.GetString("domain-name", name)
+		file := exportConf.GetString("file", domainName+".zone")
+
+		var data []byte
+		data, err = p.DomainBINDZone(domainName, int(exportConf.GetInt32("ttl", 600)))
+		if err != nil {
+			return
+		}
+
+		err = ioutil.WriteFile(file, data, 0644)
+		if err != nil {
+			return
+		}
+
+		logrus.WithField("CODE", p.Code).
+			WithField("DOMAIN", domainName).
+			WithField("FILE", file).Infoln("Domain zone exported")
+	}
+
+	return
+}
+
+// DomainBINDZone returns all records of domain with default line in BIND zone format
+func (p *Aliyun) DomainBINDZone(domainName string, defaultTTL int) (data []byte, err error) {
+
+	records, err := p.listDomainRecords(domainName, "", "")
+	if err != nil {
+		return
+	}
+
+	var exported []alidns.Record
+
+	for _, record := range records {
+
+		if record.Line != "default" {
+			logrus.WithField("CODE", p.Code).
+				WithField("DOMAIN", domainName).
+				WithField("RR", record.RR).
+				WithField("LINE", record.Line).Warnln("Domain record of non-default line could not be exported to bind zone, ignored")
+			continue
+		}
+
+		exported = append(exported, record)
+	}
+
+	data = formatBINDZone(domainName, defaultTTL, exported)
+
+	return
+}
+
+func formatBINDZone(domainName string, defaultTTL int, records []alidns.Record) []byte {
+
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].RR != records[j].RR {
+			return records[i].RR < records[j].RR
+		}
+		return records[i].Type < records[j].Type
+	})
+
+	buf := bytes.NewBuffer(nil)
+
+	fmt.Fprintf(buf, "$ORIGIN %s.\n", domainName)
+	fmt.Fprintf(buf, "$TTL %d\n", defaultTTL)
+
+	for _, record := range records {
+
+		value := record.Value
+
+		switch record.Type {
+		case "CNAME", "NS":
+			value = bindFQDN(value)
+		case "MX":
+			value = fmt.Sprintf("%d %s", record.Priority, bindFQDN(value))
+		case "SRV":
+			fields := strings.Fields(value)
+			if len(fields) == 4 {
+				fields[3] = bindFQDN(fields[3])
+				value = strings.Join(fields, " ")
+			}
+		case "TXT":
+			value = bindQuoteTXT(value)
+		}
+
+		fmt.Fprintf(buf, "%s\t%d\tIN\t%s\t%s\n", record.RR, record.TTL, record.Type, value)
+	}
+
+	return buf.Bytes()
+}
+
+// bindQuoteTXT quotes the value as BIND character-strings, the value longer than 255 bytes
+// is split into multiple strings
+func bindQuoteTXT(value string) string {
+
+	var strs []string
+
+	for {
+		chunk := value
+		if len(chunk) > 255 {
+			chunk = chunk[:255]
+		}
+
+		value = value[len(chunk):]
+
+		buf := bytes.NewBufferString("\"")
+
+		for i := 0; i < len(chunk); i++ {
+			c := chunk[i]
+			switch {
+			case c == '"' || c == '\\':
+				buf.WriteByte('\\')
+				buf.WriteByte(c)
+			case c < 0x20 || c > 0x7e:
+				fmt.Fprintf(buf, "\\%03d", c)
+			default:
+				buf.WriteByte(c)
+			}
+		}
+
+		buf.WriteByte('"')
+
+		strs = append(strs, buf.String())
+
+		if len(value) == 0 {
+			break
+		}
+	}
+
+	return strings.Join(strs, " ")
+}
+
+func bindFQDN(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+func (p *Aliyun) ImportDNSZones() (err error) {
+
+	importsConf := p.Config.GetConfig("aliyun.dns-zone.import")
+
+	if importsConf.IsEmpty() {
+		return
+	}
+
+	dryRun := p.Config.GetBoolean("aliyun.dns-zone.dry-run", false)
+
+	for _, name := range importsConf.Keys() {
+
+		importConf := importsConf.GetConfig(name)
+
+		domainName := importConf.GetString("domain-name", name)
+		file := importConf.GetString("file", domainName+".zone")
+
+		var f *os.File
+		f, err = os.Open(file)
+		if err != nil {
+			return
+		}
+
+		var records []DNSZoneRecord
+		records, err = ParseBINDZone(f, domainName, int(importConf.GetInt32("ttl", 600)))
+		f.Close()
+
+		if err != nil {
+			err = fmt.Errorf("parse bind zone file '%s' failure: %s", file, err.Error())
+			return
+		}
+
+		var changes []DNSZoneChange
+		changes, err = p.PlanDNSZoneSync(domainName, records, importConf.GetBoolean("prune", false))
+		if err != nil {
+			return
+		}
+
+		p.logDNSZoneChanges(domainName, changes, dryRun)
+
+		if dryRun {
+			continue
+		}
+
+		err = p.ApplyDNSZoneChanges(changes)
+		if err != nil {
+			return
+		}
+
+		logrus.WithField("CODE", p.Code).
+			WithField("DOMAIN", domainName).
+			WithField("FILE", file).
+			WithField("RECORDS", len(records)).Infoln("Domain zone imported")
+	}
+
+	return
+}
+
+// ParseBINDZone parses the zone file, the SOA and apex NS records are ignored
+// because they are managed by alidns
+func ParseBINDZone(reader io.Reader, domainName string, defaultTTL int) (records []DNSZoneRecord, err error) {
+
+	origin := bindFQDN(domainName)
+	ttl := defaultTTL
+	lastName := origin
+
+	scanner := bufio.NewScanner(reader)
+
+	lineNo := 0
+	pending := ""
+
+	for scanner.Scan() {
+		lineNo++
+
+		line := bindStripComment(scanner.Text())
+
+		if opens, closes := bindCountParens(pending + line); opens > closes {
+			pending += line + " "
+			continue
+		}
+
+		line = pending + line
+		pending = ""
+
+		if len(strings.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		startWithSpace := line[0] == ' ' || line[0] == '\t'
+
+		fields := bindFields(line)
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch strings.ToUpper(fields[0]) {
+		case "$ORIGIN":
+			if len(fields) < 2 {
+				err = fmt.Errorf("line %d: $ORIGIN without value", lineNo)
+				return
+			}
+			origin = bindAbsoluteName(fields[1], origin) + "."
+			continue
+		case "$TTL":
+			if len(fields) < 2 {
+				err = fmt.Errorf("line %d: $TTL without value", lineNo)
+				return
+			}
+			ttl, err = parseBINDTTL(fields[1])
+			if err != nil {
+				err = fmt.Errorf("line %d: %s", lineNo, err.Error())
+				return
+			}
+			continue
+		}
+
+		name := lastName
+
+		// the owner name is kept absolute, so the blank owner is not affected by $ORIGIN changes
+		if !startWithSpace {
+			name = bindAbsoluteName(fields[0], origin) + "."
+			fields = fields[1:]
+		}
+
+		lastName = name
+
+		recordTTL := ttl
+
+		for len(fields) > 0 {
+			if v, e := parseBINDTTL(fields[0]); e == nil {
+				recordTTL = v
+				fields = fields[1:]
+				continue
+			}
+
+			if strings.ToUpper(fields[0]) == "IN" {
+				fields = fields[1:]
+				continue
+			}
+
+			break
+		}
+
+		if len(fields) < 2 {
+			err = fmt.Errorf("line %d: record type or value is missing", lineNo)
+			return
+		}
+
+		typ := strings.ToUpper(fields[0])
+		rdata := fields[1:]
+
+		var rr string
+		rr, err = bindRelativeName(name, origin, domainName)
+		if err != nil {
+			err = fmt.Errorf("line %d: %s", lineNo, err.Error())
+			return
+		}
+
+		if typ == "SOA" || (typ == "NS" && rr == "@") {
+			continue
+		}
+
+		if !bindSupportedTypes[typ] {
+			err = fmt.Errorf("line %d: unsupported record type %s", lineNo, typ)
+			return
+		}
+
+		record := DNSZoneRecord{
+			DomainName: domainName,
+			RR:         rr,
+			Type:       typ,
+			Line:       "default",
+			TTL:        recordTTL,
+			Priority:   10,
+		}
+
+		switch typ {
+		case "CNAME", "NS":
+			record.Value = bindAbsoluteName(rdata[0], origin)
+		case "MX":
+			if len(rdata) < 2 {
+				err = fmt.Errorf("line %d: MX record should have priority and exchange", lineNo)
+				return
+			}
+			record.Priority, err = strconv.Atoi(rdata[0])
+			if err != nil {
+				err = fmt.Errorf("line %d: MX priority is illegal: %s", lineNo, rdata[0])
+				return
+			}
+			record.Value = bindAbsoluteName(rdata[1], origin)
+		case "SRV":
+			if len(rdata) < 4 {
+				err = fmt.Errorf("line %d: SRV record should have priority, weight, port and target", lineNo)
+				return
+			}
+			record.Value = strings.Join([]string{rdata[0], rdata[1], rdata[2], bindAbsoluteName(rdata[3], origin)}, " ")
+		case "TXT":
+			var parts []string
+			for _, part := range rdata {
+				parts = append(parts, bindUnquote(part))
+			}
+			record.Value = strings.Join(parts, "")
+		default:
+			record.Value = strings.Join(rdata, " ")
+		}
+
+		records = append(records, record)
+	}
+
+	err = scanner.Err()
+
+	return
+}
+
+func bindStripComment(line string) string {
+	inQuote := false
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '"':
+			inQuote = !inQuote
+		case ';':
+			if !inQuote {
+				return line[:i]
+			}
+		}
+	}
+	return line
+}
+
+// bindFields splits line by space and parentheses but keeps the quoted string as one field
+func bindFields(line string) (fields []string) {
+	var current []byte
+	inQuote := false
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\' && i+1 < len(line):
+			current = append(current, c, line[i+1])
+			i++
+		case c == '"':
+			inQuote = !inQuote
+			current = append(current, c)
+		case (c == ' ' || c == '\t' || c == '(' || c == ')') && !inQuote:
+			if len(current) > 0 {
+				fields = append(fields, string(current))
+				current = nil
+			}
+		default:
+			current = append(current, c)
+		}
+	}
+
+	if len(current) > 0 {
+		fields = append(fields, string(current))
+	}
+
+	return
+}
+
+// bindCountParens counts the parentheses out of quoted strings
+func bindCountParens(line string) (opens, closes int) {
+	inQuote := false
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '"':
+			inQuote = !inQuote
+		case '(':
+			if !inQuote {
+				opens++
+			}
+		case ')':
+			if !inQuote {
+				closes++
+			}
+		}
+	}
+	return
+}
+
+// bindUnquote removes the quotes of character-string and resolves the escapes of \X and \DDD
+func bindUnquote(str string) string {
+
+	if len(str) >= 2 && str[0] == '"' && str[len(str)-1] == '"' {
+		str = str[1 : len(str)-1]
+	}
+
+	var buf []byte
+
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+
+		if c != '\\' || i+1 >= len(str) {
+			buf = append(buf, c)
+			continue
+		}
+
+		if i+3 < len(str) && bindIsDigit(str[i+1]) && bindIsDigit(str[i+2]) && bindIsDigit(str[i+3]) {
+			n, _ := strconv.Atoi(str[i+1 : i+4])
+			buf = append(buf, byte(n))
+			i += 3
+			continue
+		}
+
+		buf = append(buf, str[i+1])
+		i++
+	}
+
+	return string(buf)
+}
+
+func bindIsDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// bindRelativeName returns the rr of name in zone of domainName, the relative name and @ are resolved
+// against the current origin, the name out of the zone is an error
+func bindRelativeName(name, origin, domainName string) (rr string, err error) {
+
+	zone := strings.ToLower(bindFQDN(domainName))
+	fqdn := bindAbsoluteName(name, origin) + "."
+
+	if strings.ToLower(fqdn) == zone {
+		rr = "@"
+		return
+	}
+
+	if !strings.HasSuffix(strings.ToLower(fqdn), "."+zone) {
+		err = fmt.Errorf("name %s is out of zone %s", name, domainName)
+		return
+	}
+
+	rr = fqdn[:len(fqdn)-len(zone)-1]
+
+	return
+}
+
+func bindAbsoluteName(name, origin string) string {
+
+	if name == "@" {
+		return strings.TrimSuffix(origin, ".")
+	}
+
+	if strings.HasSuffix(name, ".") {
+		return strings.TrimSuffix(name, ".")
+	}
+
+	return name + "." + strings.TrimSuffix(origin, ".")
+}
+
+func parseBINDTTL(str string) (ttl int, err error) {
+
+	units := map[byte]int{'s': 1, 'm': 60, 'h': 3600, 'd': 86400, 'w': 604800}
+
+	str = strings.ToLower(str)
+
+	if len(str) == 0 || str[0] < '0' || str[0] > '9' {
+		err = fmt.Errorf("ttl is illegal: %s", str)
+		return
+	}
+
+	num := 0
+
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+
+		if c >= '0' && c <= '9' {
+			num = num*10 + int(c-'0')
+			continue
+		}
+
+		unit, exist := units[c]
+		if !exist {
+			err = fmt.Errorf("ttl is illegal: %s", str)
+			return
+		}
+
+		ttl += num * unit
+		num = 0
+	}
+
+	ttl += num
+
+	return
+}
@@ -0,0 +1,137 @@
+package aliyun
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/alidns"
+)
+
+func TestParseBINDZoneNames(t *testing.T) {
+
+	cases := []struct {
+		name    string
+		zone    string
+		want    []string // rr type value
+		wantErr bool
+	}{
+		{
+			name: "apex and relative",
+			zone: "@ 600 IN A 1.1.1.1\nwww IN A 2.2.2.2\n",
+			want: []string{"@ A 1.1.1.1", "www A 2.2.2.2"},
+		},
+		{
+			name: "absolute name",
+			zone: "www.example.com. IN A 1.1.1.1\nexample.com. IN MX 5 mail\n",
+			want: []string{"www A 1.1.1.1", "@ MX mail.example.com"},
+		},
+		{
+			name: "absolute origin",
+			zone: "$ORIGIN sub.example.com.\n@ IN A 1.1.1.1\napi IN CNAME @\n",
+			want: []string{"sub A 1.1.1.1", "api.sub CNAME sub.example.com"},
+		},
+		{
+			name: "relative origin",
+			zone: "$ORIGIN sub\n@ IN A 1.1.1.1\napi IN A 2.2.2.2\n",
+			want: []string{"sub A 1.1.1.1", "api.sub A 2.2.2.2"},
+		},
+		{
+			name: "blank owner keeps previous name after origin changed",
+			zone: "www IN A 1.1.1.1\n$ORIGIN sub.example.com.\n IN A 2.2.2.2\n",
+			want: []string{"www A 1.1.1.1", "www A 2.2.2.2"},
+		},
+		{
+			name: "apex ns and soa ignored",
+			zone: "@ IN SOA ns1 admin ( 1 2 3 4 5 )\n@ IN NS ns1\ndev IN NS ns1\n",
+			want: []string{"dev NS ns1.example.com"},
+		},
+		{
+			name:    "absolute name out of zone",
+			zone:    "www.other.com. IN A 1.1.1.1\n",
+			wantErr: true,
+		},
+		{
+			name:    "origin out of zone",
+			zone:    "$ORIGIN other.com.\n@ IN A 1.1.1.1\n",
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			records, err := ParseBINDZone(strings.NewReader(c.zone), "example.com", 600)
+
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got records: %v", records)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			var got []string
+			for _, record := range records {
+				got = append(got, record.RR+" "+record.Type+" "+record.Value)
+			}
+
+			if strings.Join(got, "\n") != strings.Join(c.want, "\n") {
+				t.Fatalf("records mismatch\ngot:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(c.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestBINDTXTRoundTrip(t *testing.T) {
+
+	cases := []struct {
+		name    string
+		value   string
+		strings int
+	}{
+		{name: "plain", value: "v=spf1 include:spf.example.com ~all", strings: 1},
+		{name: "quote and backslash", value: `say "hi" \ bye`, strings: 1},
+		{name: "semicolon and parentheses", value: "a;b (c) d", strings: 1},
+		{name: "non ascii", value: "域名\ttab", strings: 1},
+		{name: "empty", value: "", strings: 1},
+		{name: "long", value: strings.Repeat("k", 600), strings: 3},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			quoted := bindQuoteTXT(c.value)
+
+			if n := len(bindFields(quoted)); n != c.strings {
+				t.Fatalf("expected %d strings, got %d: %s", c.strings, n, quoted)
+			}
+
+			for _, field := range bindFields(quoted) {
+				if len(bindUnquote(field)) > 255 {
+					t.Fatalf("string longer than 255 bytes: %s", field)
+				}
+			}
+
+			data := formatBINDZone("example.com", 600, []alidns.Record{
+				{RR: "txt", Type: "TXT", Value: c.value, TTL: 600, Line: "default"},
+			})
+
+			records, err := ParseBINDZone(bytes.NewReader(data), "example.com", 600)
+			if err != nil {
+				t.Fatalf("parse exported zone failure: %s\n%s", err, data)
+			}
+
+			if len(records) != 1 {
+				t.Fatalf("expected 1 record, got %d\n%s", len(records), data)
+			}
+
+			if records[0].RR != "txt" || records[0].Value != c.value {
+				t.Fatalf("round trip mismatch, got %s %q, want txt %q", records[0].RR, records[0].Value, c.value)
+			}
+		})
+	}
+}
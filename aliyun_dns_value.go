@@ -0,0 +1,275 @@
+package aliyun
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/vpc"
+
+	"github.com/sirupsen/logrus"
+)
+
+type DNSValueResolver func(p *Aliyun, cache *dnsValueCache, name, attr string) (string, error)
+
+var (
+	DNSValueResolvers = map[string]DNSValueResolver{
+		"slb": resolveSLBDNSValue,
+		"rds": resolveRDSDNSValue,
+		"eip": resolveEIPDNSValue,
+		"oss": resolveOSSDNSValue,
+	}
+)
+
+// dnsValueCache keeps the resources listed while resolving dns values,
+// one cache is shared by all the records of a sync, so each resource list is fetched once
+type dnsValueCache struct {
+	lbs      map[string]*SLBLoadBalancer
+	rdsInsts []DBInstanceAttribute
+	netInfos []RDSDBInstanceNetInfo
+	eips     []vpc.EipAddress
+
+	lbsLoaded      bool
+	rdsInstsLoaded bool
+	netInfosLoaded bool
+	eipsLoaded     bool
+}
+
+func (p *dnsValueCache) loadBalancers(aliyun *Aliyun) (lbs map[string]*SLBLoadBalancer, err error) {
+
+	if !p.lbsLoaded {
+		p.lbs, err = aliyun.ListLoadBalancers(false)
+		if err != nil {
+			return
+		}
+		p.lbsLoaded = true
+	}
+
+	lbs = p.lbs
+
+	return
+}
+
+func (p *dnsValueCache) rdsInstances(aliyun *Aliyun) (insts []DBInstanceAttribute, err error) {
+
+	if !p.rdsInstsLoaded {
+		p.rdsInsts, err = aliyun.DescribeRDSInstancesAttr()
+		if err != nil {
+			return
+		}
+		p.rdsInstsLoaded = true
+	}
+
+	insts = p.rdsInsts
+
+	return
+}
+
+func (p *dnsValueCache) rdsNetInfos(aliyun *Aliyun) (netInfos []RDSDBInstanceNetInfo, err error) {
+
+	if !p.netInfosLoaded {
+		p.netInfos, err = aliyun.DescribeDBInstanceNetInfo()
+		if err != nil {
+			return
+		}
+		p.netInfosLoaded = true
+	}
+
+	netInfos = p.netInfos
+
+	return
+}
+
+func (p *dnsValueCache) eipAddresses(aliyun *Aliyun) (eips []vpc.EipAddress, err error) {
+
+	if p.eipsLoaded {
+		eips = p.eips
+		return
+	}
+
+	pageNumber := 1
+
+	for {
+		req := vpc.CreateDescribeEipAddressesRequest()
+
+		req.RegionId = aliyun.Region
+		req.PageNumber = requests.NewInteger(pageNumber)
+		req.PageSize = requests.NewInteger(50)
+
+		var resp *vpc.DescribeEipAddressesResponse
+		resp, err = aliyun.VPCClient().DescribeEipAddresses(req)
+		if err != nil {
+			return
+		}
+
+		p.eips = append(p.eips, resp.EipAddresses.EipAddress...)
+
+		if len(resp.EipAddresses.EipAddress) < 50 {
+			break
+		}
+
+		pageNumber++
+	}
+
+	p.eipsLoaded = true
+
+	eips = p.eips
+
+	return
+}
+
+// resolveDNSValue resolves the value like slb://<balancer-name>/address to the live value of resource,
+// the resources listed are kept in cache for the other values of the same sync
+func (p *Aliyun) resolveDNSValue(cache *dnsValueCache, value string) (string, error) {
+
+	// only the values of registered schemes reference resources, the others like txt or url values are literal
+	idx := strings.Index(value, "://")
+	if idx <= 0 {
+		return value, nil
+	}
+
+	resolver, exist := DNSValueResolvers[value[:idx]]
+	if !exist {
+		return value, nil
+	}
+
+	valueUrl, err := url.Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("parse dns value of '%s' failure: %s", value, err.Error())
+	}
+
+	name := valueUrl.Host
+	attr := strings.Trim(valueUrl.Path, "/")
+
+	ret, err := resolver(p, cache, name, attr)
+	if err != nil {
+		return "", fmt.Errorf("resolve dns value of '%s' failure: %s", value, err.Error())
+	}
+
+	if len(ret) == 0 {
+		return "", fmt.Errorf("resolve dns value of '%s' failure: value is empty", value)
+	}
+
+	logrus.WithField("CODE", p.Code).
+		WithField("EXPR", value).
+		WithField("VALUE", ret).Debugln("DNS value resolved")
+
+	return ret, nil
+}
+
+func resolveSLBDNSValue(p *Aliyun, cache *dnsValueCache, name, attr string) (ret string, err error) {
+
+	lbs, err := cache.loadBalancers(p)
+	if err != nil {
+		return
+	}
+
+	lb, exist := lbs[name]
+	if !exist {
+		err = fmt.Errorf("slb balancer of %s not found", name)
+		return
+	}
+
+	switch attr {
+	case "", "address":
+		ret = lb.Address
+	default:
+		err = fmt.Errorf("unknown slb attribute: %s", attr)
+	}
+
+	return
+}
+
+func resolveRDSDNSValue(p *Aliyun, cache *dnsValueCache, name, attr string) (ret string, err error) {
+
+	switch attr {
+	case "", "connection-string":
+		{
+			var insts []DBInstanceAttribute
+			insts, err = cache.rdsInstances(p)
+			if err != nil {
+				return
+			}
+
+			for _, inst := range insts {
+				if inst.Name == name {
+					ret = inst.ConnectionString
+					return
+				}
+			}
+		}
+	case "public-connection-string", "private-connection-string", "intranet-connection-string":
+		{
+			ipTypes := map[string]bool{"Public": true}
+			if attr != "public-connection-string" {
+				ipTypes = map[string]bool{"Private": true, "Inner": true}
+			}
+
+			var insts []RDSDBInstanceNetInfo
+			insts, err = cache.rdsNetInfos(p)
+			if err != nil {
+				return
+			}
+
+			for _, inst := range insts {
+				if inst.InstanceName != name {
+					continue
+				}
+
+				for _, netInfo := range inst.NetInfo {
+					if ipTypes[netInfo.IPType] {
+						ret = netInfo.ConnectionString
+						return
+					}
+				}
+
+				err = fmt.Errorf("rds instance of %s has no %s", name, attr)
+				return
+			}
+		}
+	default:
+		err = fmt.Errorf("unknown rds attribute: %s", attr)
+		return
+	}
+
+	err = fmt.Errorf("rds instance of %s not found", name)
+
+	return
+}
+
+func resolveEIPDNSValue(p *Aliyun, cache *dnsValueCache, name, attr string) (ret string, err error) {
+
+	if attr != "" && attr != "address" {
+		err = fmt.Errorf("unknown eip attribute: %s", attr)
+		return
+	}
+
+	eips, err := cache.eipAddresses(p)
+	if err != nil {
+		return
+	}
+
+	for _, eip := range eips {
+		if eip.Name == name || eip.AllocationId == name {
+			ret = eip.IpAddress
+			return
+		}
+	}
+
+	err = fmt.Errorf("eip of %s not found", name)
+
+	return
+}
+
+func resolveOSSDNSValue(p *Aliyun, cache *dnsValueCache, name, attr string) (ret string, err error) {
+
+	switch attr {
+	case "", "cname":
+		ret = p.ossBucketEndpoint(name)
+	default:
+		err = fmt.Errorf("unknown oss attribute: %s", attr)
+	}
+
+	return
+}
@@ -0,0 +1,485 @@
+package aliyun
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/alidns"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DNSZoneChangeAdd    = "add"
+	DNSZoneChangeUpdate = "update"
+	DNSZoneChangeDelete = "delete"
+)
+
+type DNSZoneRecord struct {
+	DomainName string
+	RR         string
+	Type       string
+	Line       string
+	Value      string
+	TTL        int
+	Priority   int
+	Weight     int
+}
+
+func (p DNSZoneRecord) key() string {
+	return strings.Join([]string{p.RR, p.Type, p.Line}, "|")
+}
+
+func (p DNSZoneRecord) String() string {
+	str := fmt.Sprintf("%s.%s %s %s %s ttl=%d", p.RR, p.DomainName, p.Line, p.Type, p.Value, p.TTL)
+
+	if p.Type == "MX" {
+		str += fmt.Sprintf(" priority=%d", p.Priority)
+	}
+
+	if p.Weight > 0 {
+		str += fmt.Sprintf(" weight=%d", p.Weight)
+	}
+
+	return str
+}
+
+type DNSZoneChange struct {
+	Action   string
+	RecordId string
+	Owned    bool
+	Current  *DNSZoneRecord `json:",omitempty"`
+	Desired  *DNSZoneRecord `json:",omitempty"`
+}
+
+func (p DNSZoneChange) String() string {
+	switch p.Action {
+	case DNSZoneChangeAdd:
+		return "+ " + p.Desired.String()
+	case DNSZoneChangeDelete:
+		return "- " + p.Current.String()
+	}
+
+	return "~ " + p.Current.String() + " => " + p.Desired.String()
+}
+
+func dnsZoneRecordFromAli(record alidns.Record) DNSZoneRecord {
+	return DNSZoneRecord{
+		DomainName: record.DomainName,
+		RR:         record.RR,
+		Type:       record.Type,
+		Line:       record.Line,
+		Value:      record.Value,
+		TTL:        int(record.TTL),
+		Priority:   int(record.Priority),
+		Weight:     int(record.Weight),
+	}
+}
+
+func (p *Aliyun) dnsRecordRemark() string {
+	return p.signWithCode("go-flow")
+}
+
+// DesiredDNSZoneRecords returns the records declared in aliyun.dns, grouped by domain-name
+func (p *Aliyun) DesiredDNSZoneRecords() (domains map[string][]DNSZoneRecord, err error) {
+
+	dnsListConf := p.Config.GetConfig("aliyun.dns")
+
+	if dnsListConf.IsEmpty() {
+		return
+	}
+
+	ret := map[string][]DNSZoneRecord{}
+
+	valueCache := &dnsValueCache{}
+
+	for _, dnsConfName := range dnsListConf.Keys() {
+
+		dnsConf := dnsListConf.GetConfig(dnsConfName)
+
+		if dnsConf.IsEmpty() {
+			continue
+		}
+
+		domainName := dnsConf.GetString("domain-name")
+		rr := dnsConf.GetString("rr")
+		typ := dnsConf.GetString("type")
+
+		if len(domainName) == 0 || len(rr) == 0 || len(typ) == 0 {
+			err = fmt.Errorf("dns config of %s's domain-name, rr or type is empty", dnsConfName)
+			return
+		}
+
+		values := dnsConf.GetStringList("values")
+
+		if len(values) == 0 {
+			values = []string{dnsConf.GetString("value")}
+		}
+
+		for _, value := range values {
+
+			if len(value) == 0 {
+				err = fmt.Errorf("dns config of %s's value is empty", dnsConfName)
+				return
+			}
+
+			value, err = p.resolveDNSValue(valueCache, value)
+			if err != nil {
+				return
+			}
+
+			ret[domainName] = append(ret[domainName], DNSZoneRecord{
+				DomainName: domainName,
+				RR:         rr,
+				Type:       typ,
+				Line:       dnsConf.GetString("line", "default"),
+				Value:      value,
+				TTL:        int(dnsConf.GetInt32("ttl", 600)),
+				Priority:   int(dnsConf.GetInt32("priority", 10)),
+				Weight:     int(dnsConf.GetInt32("weight", 0)),
+			})
+		}
+	}
+
+	domains = ret
+
+	return
+}
+
+// PlanDNSZoneSync compares the desired records with the records of domain,
+// only records owned by this code could be updated or deleted, the records not owned
+// are left alone unless aliyun.dns-zone.adopt is true, then the ones matching desired value are taken over
+func (p *Aliyun) PlanDNSZoneSync(domainName string, desired []DNSZoneRecord, prune bool) (changes []DNSZoneChange, err error) {
+
+	records, err := p.listDomainRecords(domainName, "", "")
+	if err != nil {
+		return
+	}
+
+	adopt := p.Config.GetBoolean("aliyun.dns-zone.adopt", false)
+
+	changes = planDNSZoneChanges(desired, records, p.isSignd, prune, adopt)
+
+	return
+}
+
+func planDNSZoneChanges(desired []DNSZoneRecord, records []alidns.Record, isOwned func(remark string) bool, prune, adopt bool) (changes []DNSZoneChange) {
+
+	var desiredKeys []string
+	desiredGroups := map[string][]DNSZoneRecord{}
+
+	for _, record := range desired {
+		k := record.key()
+		if _, exist := desiredGroups[k]; !exist {
+			desiredKeys = append(desiredKeys, k)
+		}
+		desiredGroups[k] = append(desiredGroups[k], record)
+	}
+
+	currentGroups := map[string][]alidns.Record{}
+
+	for _, record := range records {
+		k := dnsZoneRecordFromAli(record).key()
+		currentGroups[k] = append(currentGroups[k], record)
+	}
+
+	for _, k := range desiredKeys {
+
+		matched := map[string]bool{}
+
+		var unmatchedDesired []DNSZoneRecord
+
+		for i, d := range desiredGroups[k] {
+
+			found := false
+
+			for _, c := range currentGroups[k] {
+				if matched[c.RecordId] || c.Value != d.Value {
+					continue
+				}
+
+				matched[c.RecordId] = true
+				found = true
+
+				current := dnsZoneRecordFromAli(c)
+				owned := isOwned(c.Remark)
+
+				if !owned && !adopt {
+					break
+				}
+
+				if owned && !dnsZoneRecordChanged(current, d) {
+					break
+				}
+
+				changes = append(changes, DNSZoneChange{
+					Action:   DNSZoneChangeUpdate,
+					RecordId: c.RecordId,
+					Owned:    owned,
+					Current:  &current,
+					Desired:  &desiredGroups[k][i],
+				})
+
+				break
+			}
+
+			if !found {
+				unmatchedDesired = append(unmatchedDesired, d)
+			}
+		}
+
+		var unmatchedOwned []alidns.Record
+
+		for _, c := range currentGroups[k] {
+			if !matched[c.RecordId] && isOwned(c.Remark) {
+				unmatchedOwned = append(unmatchedOwned, c)
+			}
+		}
+
+		for i := range unmatchedDesired {
+
+			if i < len(unmatchedOwned) {
+				current := dnsZoneRecordFromAli(unmatchedOwned[i])
+				changes = append(changes, DNSZoneChange{
+					Action:   DNSZoneChangeUpdate,
+					RecordId: unmatchedOwned[i].RecordId,
+					Owned:    true,
+					Current:  &current,
+					Desired:  &unmatchedDesired[i],
+				})
+				continue
+			}
+
+			changes = append(changes, DNSZoneChange{
+				Action:  DNSZoneChangeAdd,
+				Desired: &unmatchedDesired[i],
+			})
+		}
+
+		if !prune {
+			continue
+		}
+
+		for i := len(unmatchedDesired); i < len(unmatchedOwned); i++ {
+			current := dnsZoneRecordFromAli(unmatchedOwned[i])
+			changes = append(changes, DNSZoneChange{
+				Action:   DNSZoneChangeDelete,
+				RecordId: unmatchedOwned[i].RecordId,
+				Owned:    true,
+				Current:  &current,
+			})
+		}
+	}
+
+	if !prune {
+		return
+	}
+
+	for _, record := range records {
+
+		if !isOwned(record.Remark) {
+			continue
+		}
+
+		current := dnsZoneRecordFromAli(record)
+
+		if _, exist := desiredGroups[current.key()]; exist {
+			continue
+		}
+
+		changes = append(changes, DNSZoneChange{
+			Action:   DNSZoneChangeDelete,
+			RecordId: record.RecordId,
+			Owned:    true,
+			Current:  &current,
+		})
+	}
+
+	return
+}
+
+func dnsZoneRecordChanged(current, desired DNSZoneRecord) bool {
+
+	if current.Value != desired.Value || current.TTL != desired.TTL {
+		return true
+	}
+
+	if desired.Type == "MX" && current.Priority != desired.Priority {
+		return true
+	}
+
+	if desired.Weight > 0 && current.Weight != desired.Weight {
+		return true
+	}
+
+	return false
+}
+
+func (p *Aliyun) ApplyDNSZoneChanges(changes []DNSZoneChange) (err error) {
+
+	weightedSubDomains := map[string]bool{}
+
+	for _, change := range changes {
+
+		switch change.Action {
+		case DNSZoneChangeAdd:
+			{
+				req := alidns.CreateAddDomainRecordRequest()
+
+				req.DomainName = change.Desired.DomainName
+				req.RR = change.Desired.RR
+				req.Type = change.Desired.Type
+				req.Value = change.Desired.Value
+				req.TTL = requests.NewInteger(change.Desired.TTL)
+				req.Priority = requests.NewInteger(change.Desired.Priority)
+				req.Line = change.Desired.Line
+
+				var resp *alidns.AddDomainRecordResponse
+				resp, err = p.DNSClient().AddDomainRecord(req)
+				if err != nil {
+					err = fmt.Errorf("add domain record '%s' failure: %s", change.Desired.String(), err.Error())
+					return
+				}
+
+				change.RecordId = resp.RecordId
+			}
+		case DNSZoneChangeUpdate:
+			{
+				if change.Current.Value != change.Desired.Value ||
+					change.Current.TTL != change.Desired.TTL ||
+					(change.Desired.Type == "MX" && change.Current.Priority != change.Desired.Priority) {
+
+					req := alidns.CreateUpdateDomainRecordRequest()
+
+					req.RecordId = change.RecordId
+					req.RR = change.Desired.RR
+					req.Type = change.Desired.Type
+					req.Value = change.Desired.Value
+					req.TTL = requests.NewInteger(change.Desired.TTL)
+					req.Priority = requests.NewInteger(change.Desired.Priority)
+					req.Line = change.Desired.Line
+
+					_, err = p.DNSClient().UpdateDomainRecord(req)
+					if err != nil {
+						err = fmt.Errorf("update domain record '%s' failure: %s", change.Desired.String(), err.Error())
+						return
+					}
+				}
+			}
+		case DNSZoneChangeDelete:
+			{
+				req := alidns.CreateDeleteDomainRecordRequest()
+				req.RecordId = change.RecordId
+
+				_, err = p.DNSClient().DeleteDomainRecord(req)
+				if err != nil {
+					err = fmt.Errorf("delete domain record '%s' failure: %s", change.Current.String(), err.Error())
+					return
+				}
+			}
+		default:
+			err = fmt.Errorf("unknown dns zone change action: %s", change.Action)
+			return
+		}
+
+		if change.Action != DNSZoneChangeDelete {
+
+			if !change.Owned {
+				remarkReq := alidns.CreateUpdateDomainRecordRemarkRequest()
+				remarkReq.RecordId = change.RecordId
+				remarkReq.Remark = p.dnsRecordRemark()
+
+				_, err = p.DNSClient().UpdateDomainRecordRemark(remarkReq)
+				if err != nil {
+					return
+				}
+			}
+
+			if change.Desired.Weight > 0 &&
+				(change.Current == nil || change.Current.Weight != change.Desired.Weight) {
+
+				subDomain := change.Desired.DomainName
+
+				if change.Desired.RR != "@" {
+					subDomain = change.Desired.RR + "." + change.Desired.DomainName
+				}
+
+				if !weightedSubDomains[subDomain] {
+					slbReq := alidns.CreateSetDNSSLBStatusRequest()
+					slbReq.SubDomain = subDomain
+					slbReq.Open = requests.NewBoolean(true)
+
+					_, err = p.DNSClient().SetDNSSLBStatus(slbReq)
+					if err != nil {
+						return
+					}
+
+					weightedSubDomains[subDomain] = true
+				}
+
+				weightReq := alidns.CreateUpdateDNSSLBWeightRequest()
+				weightReq.RecordId = change.RecordId
+				weightReq.Weight = requests.NewInteger(change.Desired.Weight)
+
+				_, err = p.DNSClient().UpdateDNSSLBWeight(weightReq)
+				if err != nil {
+					return
+				}
+			}
+		}
+
+		logrus.WithField("CODE", p.Code).
+			WithField("ACTION", change.Action).
+			WithField("RECORD-ID", change.RecordId).Infoln(change.String())
+	}
+
+	return
+}
+
+func (p *Aliyun) SyncDNSZone() (err error) {
+
+	domains, err := p.DesiredDNSZoneRecords()
+	if err != nil {
+		return
+	}
+
+	prune := p.Config.GetBoolean("aliyun.dns-zone.prune", true)
+	dryRun := p.Config.GetBoolean("aliyun.dns-zone.dry-run", false)
+
+	for domainName, desired := range domains {
+
+		var changes []DNSZoneChange
+		changes, err = p.PlanDNSZoneSync(domainName, desired, prune)
+		if err != nil {
+			return
+		}
+
+		if len(changes) == 0 {
+			logrus.WithField("CODE", p.Code).WithField("DOMAIN", domainName).Infoln("Domain records already in sync")
+			continue
+		}
+
+		p.logDNSZoneChanges(domainName, changes, dryRun)
+
+		if dryRun {
+			continue
+		}
+
+		err = p.ApplyDNSZoneChanges(changes)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (p *Aliyun) logDNSZoneChanges(domainName string, changes []DNSZoneChange, dryRun bool) {
+	for _, change := range changes {
+		logrus.WithField("CODE", p.Code).
+			WithField("DOMAIN", domainName).
+			WithField("ACTION", change.Action).
+			WithField("DRY-RUN", dryRun).Infoln("Planned domain record change: " + change.String())
+	}
+}
@@ -0,0 +1,112 @@
+package aliyun
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/alidns"
+)
+
+func TestPlanDNSZoneChanges(t *testing.T) {
+
+	isOwned := func(remark string) bool {
+		return strings.HasSuffix(remark, "[test]")
+	}
+
+	desired := func(rr, value string, ttl int) DNSZoneRecord {
+		return DNSZoneRecord{DomainName: "example.com", RR: rr, Type: "A", Line: "default", Value: value, TTL: ttl}
+	}
+
+	current := func(id, rr, value string, ttl int64, remark string) alidns.Record {
+		return alidns.Record{RecordId: id, DomainName: "example.com", RR: rr, Type: "A", Line: "default", Value: value, TTL: ttl, Remark: remark}
+	}
+
+	cases := []struct {
+		name    string
+		desired []DNSZoneRecord
+		records []alidns.Record
+		prune   bool
+		adopt   bool
+		want    []string // action record id
+	}{
+		{
+			name:    "add missing record",
+			desired: []DNSZoneRecord{desired("www", "1.1.1.1", 600)},
+			want:    []string{"add "},
+		},
+		{
+			name:    "owned record unchanged",
+			desired: []DNSZoneRecord{desired("www", "1.1.1.1", 600)},
+			records: []alidns.Record{current("r1", "www", "1.1.1.1", 600, "[test]")},
+		},
+		{
+			name:    "owned record ttl changed",
+			desired: []DNSZoneRecord{desired("www", "1.1.1.1", 300)},
+			records: []alidns.Record{current("r1", "www", "1.1.1.1", 600, "[test]")},
+			want:    []string{"update r1"},
+		},
+		{
+			name:    "owned record value changed",
+			desired: []DNSZoneRecord{desired("www", "2.2.2.2", 600)},
+			records: []alidns.Record{current("r1", "www", "1.1.1.1", 600, "[test]")},
+			want:    []string{"update r1"},
+		},
+		{
+			name:    "unowned record with same value left alone",
+			desired: []DNSZoneRecord{desired("www", "1.1.1.1", 300)},
+			records: []alidns.Record{current("r1", "www", "1.1.1.1", 600, "")},
+		},
+		{
+			name:    "unowned record with same value adopted",
+			desired: []DNSZoneRecord{desired("www", "1.1.1.1", 600)},
+			records: []alidns.Record{current("r1", "www", "1.1.1.1", 600, "")},
+			adopt:   true,
+			want:    []string{"update r1"},
+		},
+		{
+			name:    "unowned record with other value never updated",
+			desired: []DNSZoneRecord{desired("www", "2.2.2.2", 600)},
+			records: []alidns.Record{current("r1", "www", "1.1.1.1", 600, "")},
+			prune:   true,
+			adopt:   true,
+			want:    []string{"add "},
+		},
+		{
+			name:    "owned records not desired kept without prune",
+			desired: []DNSZoneRecord{desired("www", "1.1.1.1", 600)},
+			records: []alidns.Record{
+				current("r1", "www", "1.1.1.1", 600, "[test]"),
+				current("r2", "www", "2.2.2.2", 600, "[test]"),
+				current("r3", "old", "3.3.3.3", 600, "[test]"),
+			},
+		},
+		{
+			name:    "owned records not desired deleted with prune",
+			desired: []DNSZoneRecord{desired("www", "1.1.1.1", 600)},
+			records: []alidns.Record{
+				current("r1", "www", "1.1.1.1", 600, "[test]"),
+				current("r2", "www", "2.2.2.2", 600, "[test]"),
+				current("r3", "old", "3.3.3.3", 600, "[test]"),
+				current("r4", "manual", "4.4.4.4", 600, ""),
+			},
+			prune: true,
+			want:  []string{"delete r2", "delete r3"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			changes := planDNSZoneChanges(c.desired, c.records, isOwned, c.prune, c.adopt)
+
+			var got []string
+			for _, change := range changes {
+				got = append(got, change.Action+" "+change.RecordId)
+			}
+
+			if strings.Join(got, "\n") != strings.Join(c.want, "\n") {
+				t.Fatalf("changes mismatch\ngot:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(c.want, "\n"))
+			}
+		})
+	}
+}
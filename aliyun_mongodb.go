@@ -0,0 +1,509 @@
+package aliyun
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/dds"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/vpc"
+
+	"github.com/sirupsen/logrus"
+)
+
+type MongoDBInstance struct {
+	Name string
+	URI  string
+	dds.DBInstance
+}
+
+// mongoDBTerminalStatus is the status of mongodb instance which would not reach to others while waiting
+var mongoDBTerminalStatus = []string{"Deleting"}
+
+func (p *Aliyun) listMongoDBInstances(tags map[string]string) (insts []MongoDBInstance, err error) {
+
+	var reqTags []dds.DescribeDBInstancesTag
+
+	for k, v := range p.codeTags(tags) {
+		reqTags = append(reqTags, dds.DescribeDBInstancesTag{Key: k, Value: v})
+	}
+
+	// the replicate and sharding instances could not be listed together
+	for _, instType := range []string{"replicate", "sharding"} {
+
+		count := 0
+
+		for pageNumber := 1; ; pageNumber++ {
+
+			req := dds.CreateDescribeDBInstancesRequest()
+
+			req.RegionId = p.Region
+			req.DBInstanceType = instType
+			req.Tag = &reqTags
+			req.PageSize = requests.NewInteger(50)
+			req.PageNumber = requests.NewInteger(pageNumber)
+
+			var resp *dds.DescribeDBInstancesResponse
+			resp, err = p.MongoDBClient().DescribeDBInstances(req)
+			if err != nil {
+				return
+			}
+
+			for _, inst := range resp.DBInstances.DBInstance {
+				insts = append(insts, MongoDBInstance{Name: inst.DBInstanceDescription, DBInstance: inst})
+			}
+
+			count += len(resp.DBInstances.DBInstance)
+
+			if len(resp.DBInstances.DBInstance) == 0 || count >= resp.TotalCount {
+				break
+			}
+		}
+	}
+
+	return
+}
+
+func (p *Aliyun) FindMongoDBInstance(mongoName string) (inst *MongoDBInstance, err error) {
+
+	insts, err := p.listMongoDBInstances(map[string]string{"name": mongoName})
+	if err != nil {
+		return
+	}
+
+	if len(insts) == 0 {
+		return
+	}
+
+	inst = &insts[0]
+
+	return
+}
+
+// DescribeMongoDBInstances describes the instances of code with connection uri,
+// the uri contains user of root without password
+func (p *Aliyun) DescribeMongoDBInstances() (insts []MongoDBInstance, err error) {
+
+	insts, err = p.listMongoDBInstances(nil)
+	if err != nil {
+		return
+	}
+
+	for i := range insts {
+		insts[i].URI, err = p.mongoDBURI(insts[i].DBInstanceId, insts[i].DBInstanceType)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (p *Aliyun) mongoDBURI(dbInstanceId, instType string) (uri string, err error) {
+
+	var hosts []string
+	query := ""
+
+	if instType == "sharding" {
+
+		req := dds.CreateDescribeShardingNetworkAddressRequest()
+		req.DBInstanceId = dbInstanceId
+
+		var resp *dds.DescribeShardingNetworkAddressResponse
+		resp, err = p.MongoDBClient().DescribeShardingNetworkAddress(req)
+		if err != nil {
+			return
+		}
+
+		for _, addr := range resp.NetworkAddresses.NetworkAddress {
+			if addr.NetworkType == "VPC" && addr.NodeType == "mongos" {
+				hosts = append(hosts, addr.NetworkAddress+":"+addr.Port)
+			}
+		}
+	} else {
+
+		req := dds.CreateDescribeDBInstanceAttributeRequest()
+		req.DBInstanceId = dbInstanceId
+
+		var resp *dds.DescribeDBInstanceAttributeResponse
+		resp, err = p.MongoDBClient().DescribeDBInstanceAttribute(req)
+		if err != nil {
+			return
+		}
+
+		if len(resp.DBInstances.DBInstance) == 0 {
+			err = fmt.Errorf("mongodb instance %s not found", dbInstanceId)
+			return
+		}
+
+		attr := resp.DBInstances.DBInstance[0]
+
+		for _, replicaSet := range attr.ReplicaSets.ReplicaSet {
+			if replicaSet.NetworkType == "VPC" {
+				hosts = append(hosts, replicaSet.ConnectionDomain+":"+replicaSet.ConnectionPort)
+			}
+		}
+
+		if len(attr.ReplicaSetName) > 0 {
+			query = "?replicaSet=" + attr.ReplicaSetName
+		}
+	}
+
+	if len(hosts) == 0 {
+		return
+	}
+
+	uri = fmt.Sprintf("mongodb://root@%s/admin%s", strings.Join(hosts, ","), query)
+
+	return
+}
+
+func (p *Aliyun) CreateMongoDBInstances() (err error) {
+
+	mongosConf := p.Config.GetConfig("aliyun.mongodb")
+
+	if mongosConf.IsEmpty() {
+		return
+	}
+
+	var createdIds, createdNames []string
+
+	for _, mongoName := range mongosConf.Keys() {
+
+		mongoConf := mongosConf.GetConfig(mongoName)
+
+		var inst *MongoDBInstance
+		inst, err = p.FindMongoDBInstance(mongoName)
+		if err != nil {
+			return
+		}
+
+		if inst != nil {
+			logrus.WithField("CODE", p.Code).
+				WithField("MONGODB-INSTANCE-ID", inst.DBInstanceId).
+				WithField("MONGODB-INSTANCE-NAME", mongoName).Infoln("MongoDB instance already created")
+			continue
+		}
+
+		vpcName := mongoConf.GetString("vpc-name")
+		vSwitchName := mongoConf.GetString("vswitch-name")
+
+		if len(vpcName) == 0 || len(vSwitchName) == 0 {
+			err = fmt.Errorf("mongodb config of %s's vpc-name or vswitch-name is empty", mongoName)
+			return
+		}
+
+		var vSwitch *vpc.VSwitch
+		vSwitch, err = p.FindVSwitch(vpcName, vSwitchName)
+		if err != nil {
+			return
+		}
+
+		if vSwitch == nil {
+			err = fmt.Errorf("mongodb instance of %s vswitch is not found", mongoName)
+			return
+		}
+
+		var password, fnName string
+		password, fnName, err = p.tryInvokeEnvFunc(fmt.Sprintf("mongodb.%s.password", mongoName), mongoConf.GetString("password"))
+		if err != nil {
+			return
+		}
+
+		if len(password) == 0 {
+			err = fmt.Errorf("mongodb config of %s's password is empty", mongoName)
+			return
+		}
+
+		instType := mongoConf.GetString("type", "replicate")
+
+		var instId string
+
+		switch instType {
+		case "replicate":
+			{
+				req := dds.CreateCreateDBInstanceRequest()
+
+				req.RegionId = p.Region
+				req.ZoneId = mongoConf.GetString("zone-id", vSwitch.ZoneId)
+				req.Engine = "MongoDB"
+				req.EngineVersion = mongoConf.GetString("engine-version", "4.0")
+				req.DBInstanceClass = mongoConf.GetString("instance-class", "dds.mongo.mid")
+				req.DBInstanceStorage = requests.NewInteger(int(mongoConf.GetInt32("instance-storage", 10)))
+				req.ReplicationFactor = mongoConf.GetString("replication-factor", "3")
+				req.DBInstanceDescription = mongoName
+				req.AccountPassword = password
+				req.ChargeType = mongoConf.GetString("charge-type", "PostPaid")
+				req.NetworkType = "VPC"
+				req.VpcId = vSwitch.VpcId
+				req.VSwitchId = vSwitch.VSwitchId
+
+				var resp *dds.CreateDBInstanceResponse
+				resp, err = p.MongoDBClient().CreateDBInstance(req)
+				if err != nil {
+					err = fmt.Errorf("create mongodb instance '%s' failure: %s", mongoName, err.Error())
+					return
+				}
+
+				instId = resp.DBInstanceId
+			}
+		case "sharding":
+			{
+				shardingConf := mongoConf.GetConfig("sharding")
+
+				var mongos []dds.CreateShardingDBInstanceMongos
+				for i := 0; i < int(shardingConf.GetInt32("mongos.count", 2)); i++ {
+					mongos = append(mongos, dds.CreateShardingDBInstanceMongos{
+						Class: shardingConf.GetString("mongos.class", "dds.mongos.mid"),
+					})
+				}
+
+				var shards []dds.CreateShardingDBInstanceReplicaSet
+				for i := 0; i < int(shardingConf.GetInt32("shard.count", 2)); i++ {
+					shards = append(shards, dds.CreateShardingDBInstanceReplicaSet{
+						Class:   shardingConf.GetString("shard.class", "dds.shard.mid"),
+						Storage: fmt.Sprintf("%d", shardingConf.GetInt32("shard.storage", 10)),
+					})
+				}
+
+				configServers := []dds.CreateShardingDBInstanceConfigServer{
+					{
+						Class:   shardingConf.GetString("config-server.class", "dds.cs.mid"),
+						Storage: fmt.Sprintf("%d", shardingConf.GetInt32("config-server.storage", 20)),
+					},
+				}
+
+				req := dds.CreateCreateShardingDBInstanceRequest()
+
+				req.RegionId = p.Region
+				req.ZoneId = mongoConf.GetString("zone-id", vSwitch.ZoneId)
+				req.Engine = "MongoDB"
+				req.EngineVersion = mongoConf.GetString("engine-version", "4.0")
+				req.DBInstanceDescription = mongoName
+				req.AccountPassword = password
+				req.ChargeType = mongoConf.GetString("charge-type", "PostPaid")
+				req.NetworkType = "VPC"
+				req.VpcId = vSwitch.VpcId
+				req.VSwitchId = vSwitch.VSwitchId
+				req.Mongos = &mongos
+				req.ReplicaSet = &shards
+				req.ConfigServer = &configServers
+
+				var resp *dds.CreateShardingDBInstanceResponse
+				resp, err = p.MongoDBClient().CreateShardingDBInstance(req)
+				if err != nil {
+					err = fmt.Errorf("create mongodb sharding instance '%s' failure: %s", mongoName, err.Error())
+					return
+				}
+
+				instId = resp.DBInstanceId
+			}
+		default:
+			err = fmt.Errorf("unknown type %s of mongodb %s, it should be replicate or sharding", instType, mongoName)
+			return
+		}
+
+		// the instance without tags could not be found by name, report its id to clean it up
+		err = p.addMongoDBInstanceTags(instId, mongoName)
+		if err != nil {
+			err = fmt.Errorf("tag created mongodb instance '%s' (%s) failure, it should be tagged or deleted manually: %s", mongoName, instId, err.Error())
+			return
+		}
+
+		if len(fnName) > 0 {
+			setENV(fmt.Sprintf("mongodb_%s_password", mongoName), password)
+		}
+
+		createdIds = append(createdIds, instId)
+		createdNames = append(createdNames, mongoName)
+
+		logrus.WithField("CODE", p.Code).
+			WithField("MONGODB-INSTANCE-ID", instId).
+			WithField("MONGODB-INSTANCE-NAME", mongoName).
+			WithField("MONGODB-INSTANCE-TYPE", instType).
+			WithField("MONGODB-VSWITCH-ID", vSwitch.VSwitchId).
+			Infoln("MongoDB instance created")
+	}
+
+	// the whitelist could not be modified before the instance is running
+	err = p.waitResources(context.Background(), "mongodb", createdIds, createdNames, func(ctx context.Context, instId string) error {
+		return p.WaitForMongoDBInstanceContext(ctx, instId, "Running", 60*30)
+	})
+	if err != nil {
+		return
+	}
+
+	err = p.SyncMongoDBWhitelist()
+
+	return
+}
+
+func (p *Aliyun) addMongoDBInstanceTags(instanceId, name string) (err error) {
+
+	req := dds.CreateTagResourcesRequest()
+
+	req.RegionId = p.Region
+	req.ResourceType = "INSTANCE"
+	req.ResourceId = &[]string{instanceId}
+	req.Tag = &[]dds.TagResourcesTag{
+		{Key: "code", Value: p.Code},
+		{Key: "creator", Value: "go-flow"},
+		{Key: "name", Value: name},
+	}
+
+	_, err = p.MongoDBClient().TagResources(req)
+
+	return
+}
+
+func (p *Aliyun) SyncMongoDBWhitelist() (err error) {
+
+	mongosConf := p.Config.GetConfig("aliyun.mongodb")
+
+	for _, mongoName := range mongosConf.Keys() {
+
+		mongoConf := mongosConf.GetConfig(mongoName)
+
+		whitelistConf := mongoConf.GetConfig("whitelist")
+
+		if whitelistConf.IsEmpty() {
+			continue
+		}
+
+		var inst *MongoDBInstance
+		inst, err = p.FindMongoDBInstance(mongoName)
+		if err != nil {
+			return
+		}
+
+		if inst == nil {
+			err = fmt.Errorf("mongodb instance of %s not exist", mongoName)
+			return
+		}
+
+		err = p.syncWhitelistGroups("mongodb "+mongoName, mongoConf.GetString("vpc-name"), whitelistConf, nil,
+			func(groupName, securityIps string) error {
+
+				req := dds.CreateModifySecurityIpsRequest()
+
+				req.DBInstanceId = inst.DBInstanceId
+				req.SecurityIpGroupName = groupName
+				req.SecurityIps = securityIps
+				req.ModifyMode = "Cover"
+
+				_, e := p.MongoDBClient().ModifySecurityIps(req)
+
+				return e
+			},
+			func() error {
+				return p.WaitForMongoDBInstance(inst.DBInstanceId, "Running", 300)
+			},
+		)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (p *Aliyun) DeleteMongoDBInstances() (err error) {
+
+	mongosConf := p.Config.GetConfig("aliyun.mongodb")
+
+	for _, mongoName := range mongosConf.Keys() {
+
+		var inst *MongoDBInstance
+		inst, err = p.FindMongoDBInstance(mongoName)
+		if err != nil {
+			return
+		}
+
+		if inst == nil {
+			continue
+		}
+
+		req := dds.CreateDeleteDBInstanceRequest()
+		req.DBInstanceId = inst.DBInstanceId
+
+		_, err = p.MongoDBClient().DeleteDBInstance(req)
+		if err != nil {
+			err = fmt.Errorf("delete mongodb instance '%s' failure: %s", mongoName, err.Error())
+			return
+		}
+
+		logrus.WithField("CODE", p.Code).
+			WithField("MONGODB-INSTANCE-ID", inst.DBInstanceId).
+			WithField("MONGODB-INSTANCE-NAME", mongoName).Infoln("MongoDB instance deleted")
+	}
+
+	return
+}
+
+func (p *Aliyun) mongoDBInstanceStatus(instanceId string) (status string, err error) {
+
+	req := dds.CreateDescribeDBInstanceAttributeRequest()
+	req.DBInstanceId = instanceId
+
+	resp, err := p.MongoDBClient().DescribeDBInstanceAttribute(req)
+
+	if IsAliErrCode(err, "InvalidDBInstanceId.NotFound") {
+		return WaitNotFound, nil
+	}
+
+	if err != nil {
+		return
+	}
+
+	if len(resp.DBInstances.DBInstance) == 0 {
+		status = WaitNotFound
+		return
+	}
+
+	status = resp.DBInstances.DBInstance[0].DBInstanceStatus
+
+	return
+}
+
+func (p *Aliyun) WaitForMongoDBInstance(instanceId, status string, timeout int) error {
+	return p.WaitForMongoDBInstanceContext(context.Background(), instanceId, status, timeout)
+}
+
+func (p *Aliyun) WaitForMongoDBInstanceContext(ctx context.Context, instanceId, status string, timeout int) error {
+
+	if timeout <= 0 {
+		timeout = 120
+	}
+
+	waiter := p.newWaiter(fmt.Sprintf("mongodb instance %s to %s", instanceId, status), time.Duration(timeout)*time.Second,
+		[]string{status}, terminalStatusExcept(mongoDBTerminalStatus, status)...)
+	waiter.Fields["MONGODB-INSTANCE-ID"] = instanceId
+
+	return waiter.Wait(ctx, func() (string, error) {
+		return p.mongoDBInstanceStatus(instanceId)
+	})
+}
+
+// WaitForMongoDBInstances waits for all mongodb instances of code to given status concurrently
+func (p *Aliyun) WaitForMongoDBInstances(ctx context.Context, status string, timeout int) (err error) {
+
+	insts, err := p.listMongoDBInstances(nil)
+	if err != nil {
+		return
+	}
+
+	var instIds, names []string
+
+	for _, inst := range insts {
+		instIds = append(instIds, inst.DBInstanceId)
+		names = append(names, inst.Name)
+	}
+
+	err = p.waitResources(ctx, "mongodb", instIds, names, func(ctx context.Context, instId string) error {
+		return p.WaitForMongoDBInstanceContext(ctx, instId, status, timeout)
+	})
+
+	return
+}
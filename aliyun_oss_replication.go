@@ -0,0 +1,334 @@
+package aliyun
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+	"github.com/gogap/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+type OSSReplicationDestination struct {
+	Bucket       string `xml:"Bucket"`
+	Location     string `xml:"Location"`
+	TransferType string `xml:"TransferType,omitempty"`
+}
+
+type OSSReplicationProgressInfo struct {
+	HistoricalObject string `xml:"HistoricalObject,omitempty"`
+	NewObject        string `xml:"NewObject,omitempty"`
+}
+
+type OSSReplicationRule struct {
+	ID                          string                      `xml:"ID,omitempty"`
+	Prefixes                    []string                    `xml:"PrefixSet>Prefix,omitempty"`
+	Action                      string                      `xml:"Action,omitempty"`
+	Destination                 OSSReplicationDestination   `xml:"Destination"`
+	Status                      string                      `xml:"Status,omitempty"`
+	HistoricalObjectReplication string                      `xml:"HistoricalObjectReplication,omitempty"`
+	Progress                    *OSSReplicationProgressInfo `xml:"Progress,omitempty"`
+}
+
+type OSSReplicationConfiguration struct {
+	XMLName xml.Name             `xml:"ReplicationConfiguration"`
+	Rules   []OSSReplicationRule `xml:"Rule"`
+}
+
+type OSSReplicationProgress struct {
+	XMLName xml.Name             `xml:"ReplicationProgress"`
+	Bucket  string               `xml:"-"`
+	Rules   []OSSReplicationRule `xml:"Rule"`
+}
+
+func (p *Aliyun) ossClientOfRegion(region string) (client *oss.Client, err error) {
+
+	if region == p.Region {
+		client = p.OSSClient()
+		return
+	}
+
+	endpoint := fmt.Sprintf("oss-%s.aliyuncs.com", region)
+
+	client, err = oss.New(endpoint, p.AccessKeyId, p.AccessKeySecret)
+
+	return
+}
+
+func (p *Aliyun) SetupOSSReplication() (err error) {
+
+	ossConf := p.Config.GetConfig("aliyun.oss.bucket")
+
+	if ossConf.IsEmpty() {
+		return
+	}
+
+	for _, key := range ossConf.Keys() {
+
+		bucketName := ossConf.GetString(key+".name", key)
+
+		replicationConf := ossConf.GetConfig(key + ".replication")
+
+		if replicationConf.IsEmpty() {
+			continue
+		}
+
+		var rule OSSReplicationRule
+		rule, err = p.ossReplicationRule(bucketName, replicationConf)
+		if err != nil {
+			return
+		}
+
+		err = p.createOSSReplicationDestBucket(rule.Destination, replicationConf)
+		if err != nil {
+			return
+		}
+
+		var current OSSReplicationConfiguration
+		current, err = p.getOSSReplication(bucketName)
+		if err != nil {
+			return
+		}
+
+		var existRule *OSSReplicationRule
+		for i, r := range current.Rules {
+			if r.ID == rule.ID ||
+				(r.Destination.Bucket == rule.Destination.Bucket && r.Destination.Location == rule.Destination.Location) {
+				existRule = &current.Rules[i]
+				break
+			}
+		}
+
+		if existRule != nil {
+
+			diffs := ossReplicationRuleDiffs(*existRule, rule)
+
+			if len(diffs) > 0 {
+				err = fmt.Errorf("bucket '%s' replication rule '%s' differs from config (%s), the rule could not be modified in place, delete it before setup again",
+					bucketName, existRule.ID, strings.Join(diffs, ", "))
+				return
+			}
+
+			logrus.WithField("code", p.Code).
+				WithField("bucket", bucketName).
+				WithField("dest-bucket", rule.Destination.Bucket).
+				WithField("dest-location", rule.Destination.Location).
+				Infoln("bucket replication already configured")
+			continue
+		}
+
+		var data []byte
+		data, err = xml.Marshal(OSSReplicationConfiguration{Rules: []OSSReplicationRule{rule}})
+		if err != nil {
+			return
+		}
+
+		err = p.OSSClient().PutBucketReplication(bucketName, string(data))
+		if err != nil {
+			err = fmt.Errorf("put bucket '%s' replication failure: %s", bucketName, err.Error())
+			return
+		}
+
+		logrus.WithField("code", p.Code).
+			WithField("bucket", bucketName).
+			WithField("rule-id", rule.ID).
+			WithField("dest-bucket", rule.Destination.Bucket).
+			WithField("dest-location", rule.Destination.Location).
+			Infoln("bucket replication configured")
+	}
+
+	return
+}
+
+func (p *Aliyun) ossReplicationRule(bucketName string, replicationConf config.Configuration) (rule OSSReplicationRule, err error) {
+
+	destRegion := replicationConf.GetString("dest-region")
+	destBucket := replicationConf.GetString("dest-bucket")
+
+	if len(destRegion) == 0 || len(destBucket) == 0 {
+		err = fmt.Errorf("replication config of bucket %s's dest-region or dest-bucket is empty", bucketName)
+		return
+	}
+
+	if destRegion == p.Region {
+		err = fmt.Errorf("replication config of bucket %s's dest-region should not be the same as source region", bucketName)
+		return
+	}
+
+	historical := "disabled"
+	if replicationConf.GetBoolean("historical", true) {
+		historical = "enabled"
+	}
+
+	actions := replicationConf.GetStringList("actions")
+	if len(actions) == 0 {
+		actions = []string{"ALL"}
+	}
+
+	rule = OSSReplicationRule{
+		ID:       replicationConf.GetString("rule-id", fmt.Sprintf("%s-to-%s", bucketName, destBucket)),
+		Prefixes: replicationConf.GetStringList("prefixes"),
+		Action:   strings.Join(actions, ","),
+		Destination: OSSReplicationDestination{
+			Bucket:       destBucket,
+			Location:     "oss-" + destRegion,
+			TransferType: replicationConf.GetString("transfer-type"),
+		},
+		HistoricalObjectReplication: historical,
+	}
+
+	return
+}
+
+// ossReplicationRuleDiffs returns the prefixes and action differences between the existing rule and the rule of config,
+// the order of prefixes and actions is ignored
+func ossReplicationRuleDiffs(current, desired OSSReplicationRule) (diffs []string) {
+
+	if !ossStringSetEqual(current.Prefixes, desired.Prefixes) {
+		diffs = append(diffs, fmt.Sprintf("prefixes %v => %v", current.Prefixes, desired.Prefixes))
+	}
+
+	currentActions := strings.Split(current.Action, ",")
+	desiredActions := strings.Split(desired.Action, ",")
+
+	if !ossStringSetEqual(currentActions, desiredActions) {
+		diffs = append(diffs, fmt.Sprintf("action %s => %s", current.Action, desired.Action))
+	}
+
+	return
+}
+
+func ossStringSetEqual(a, b []string) bool {
+
+	set := map[string]bool{}
+
+	for _, v := range a {
+		set[strings.TrimSpace(v)] = true
+	}
+
+	for _, v := range b {
+		if !set[strings.TrimSpace(v)] {
+			return false
+		}
+	}
+
+	for _, v := range b {
+		delete(set, strings.TrimSpace(v))
+	}
+
+	return len(set) == 0
+}
+
+func (p *Aliyun) createOSSReplicationDestBucket(dest OSSReplicationDestination, replicationConf config.Configuration) (err error) {
+
+	destRegion := strings.TrimPrefix(dest.Location, "oss-")
+
+	client, err := p.ossClientOfRegion(destRegion)
+	if err != nil {
+		return
+	}
+
+	exist, err := client.IsBucketExist(dest.Bucket)
+	if err != nil {
+		return
+	}
+
+	if exist {
+		return
+	}
+
+	err = client.CreateBucket(dest.Bucket)
+	if err != nil {
+		err = fmt.Errorf("create replication dest bucket '%s' in region %s failure: %s", dest.Bucket, destRegion, err.Error())
+		return
+	}
+
+	logrus.WithField("code", p.Code).
+		WithField("bucket", dest.Bucket).
+		WithField("region", destRegion).
+		Infoln("replication dest bucket created")
+
+	return
+}
+
+func (p *Aliyun) getOSSReplication(bucketName string) (conf OSSReplicationConfiguration, err error) {
+
+	data, err := p.OSSClient().GetBucketReplication(bucketName)
+
+	if err != nil {
+		if strings.Contains(err.Error(), "ErrorCode=NoSuchReplicationConfiguration") {
+			err = nil
+		}
+		return
+	}
+
+	err = xml.Unmarshal([]byte(data), &conf)
+
+	return
+}
+
+func (p *Aliyun) DescribeOSSReplicationProgress() (progresses []OSSReplicationProgress, err error) {
+
+	ossConf := p.Config.GetConfig("aliyun.oss.bucket")
+
+	if ossConf.IsEmpty() {
+		return
+	}
+
+	for _, key := range ossConf.Keys() {
+
+		bucketName := ossConf.GetString(key+".name", key)
+
+		if ossConf.GetConfig(key + ".replication").IsEmpty() {
+			continue
+		}
+
+		var current OSSReplicationConfiguration
+		current, err = p.getOSSReplication(bucketName)
+		if err != nil {
+			return
+		}
+
+		progress := OSSReplicationProgress{Bucket: bucketName}
+
+		for _, rule := range current.Rules {
+
+			var data string
+			data, err = p.OSSClient().GetBucketReplicationProgress(bucketName, rule.ID)
+			if err != nil {
+				err = fmt.Errorf("get bucket '%s' replication progress of rule '%s' failure: %s", bucketName, rule.ID, err.Error())
+				return
+			}
+
+			var ruleProgress OSSReplicationProgress
+			err = xml.Unmarshal([]byte(data), &ruleProgress)
+			if err != nil {
+				return
+			}
+
+			for _, r := range ruleProgress.Rules {
+
+				entry := logrus.WithField("code", p.Code).
+					WithField("bucket", bucketName).
+					WithField("rule-id", r.ID).
+					WithField("dest-bucket", r.Destination.Bucket).
+					WithField("status", r.Status)
+
+				if r.Progress != nil {
+					entry = entry.WithField("historical", r.Progress.HistoricalObject).
+						WithField("new-object", r.Progress.NewObject)
+				}
+
+				entry.Infoln("bucket replication progress")
+			}
+
+			progress.Rules = append(progress.Rules, ruleProgress.Rules...)
+		}
+
+		progresses = append(progresses, progress)
+	}
+
+	return
+}
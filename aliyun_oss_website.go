@@ -0,0 +1,168 @@
+package aliyun
+
+import (
+	"fmt"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/alidns"
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+	"github.com/gogap/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+func (p *Aliyun) SetupOSSWebsite() (err error) {
+
+	ossConf := p.Config.GetConfig("aliyun.oss.bucket")
+
+	if ossConf.IsEmpty() {
+		return
+	}
+
+	for _, key := range ossConf.Keys() {
+
+		bucketName := ossConf.GetString(key+".name", key)
+
+		websiteConf := ossConf.GetConfig(key + ".website")
+
+		if websiteConf.IsEmpty() {
+			continue
+		}
+
+		var exist bool
+		exist, err = p.OSSClient().IsBucketExist(bucketName)
+		if err != nil {
+			return
+		}
+
+		if !exist {
+			err = fmt.Errorf("bucket of %s not exist", bucketName)
+			return
+		}
+
+		var websiteXML oss.WebsiteXML
+		websiteXML, err = p.ossWebsiteXML(bucketName, websiteConf)
+		if err != nil {
+			return
+		}
+
+		err = p.OSSClient().SetBucketWebsiteDetail(bucketName, websiteXML)
+		if err != nil {
+			err = fmt.Errorf("set bucket '%s' website failure: %s", bucketName, err.Error())
+			return
+		}
+
+		logrus.WithField("code", p.Code).
+			WithField("bucket", bucketName).
+			WithField("index", websiteXML.IndexDocument.Suffix).
+			WithField("error", websiteXML.ErrorDocument.Key).
+			Infoln("bucket website configured")
+
+		domainsConf := websiteConf.GetConfig("domain")
+
+		for _, domainKey := range domainsConf.Keys() {
+			err = p.bindOSSWebsiteDomain(bucketName, domainsConf.GetConfig(domainKey))
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+func (p *Aliyun) ossWebsiteXML(bucketName string, websiteConf config.Configuration) (websiteXML oss.WebsiteXML, err error) {
+
+	websiteXML.IndexDocument.Suffix = websiteConf.GetString("index-document", "index.html")
+	websiteXML.ErrorDocument.Key = websiteConf.GetString("error-document")
+
+	rulesConf := websiteConf.GetConfig("routing-rules")
+
+	for i, ruleName := range rulesConf.Keys() {
+
+		ruleConf := rulesConf.GetConfig(ruleName)
+
+		redirectType := ruleConf.GetString("redirect.type", "External")
+
+		passQueryString := ruleConf.GetBoolean("redirect.pass-query-string", false)
+
+		rule := oss.RoutingRule{
+			RuleNumber: int(ruleConf.GetInt32("number", int32(i+1))),
+			Condition: oss.Condition{
+				KeyPrefixEquals:             ruleConf.GetString("condition.key-prefix-equals"),
+				HTTPErrorCodeReturnedEquals: int(ruleConf.GetInt32("condition.http-error-code-returned-equals")),
+			},
+			Redirect: oss.Redirect{
+				RedirectType:         redirectType,
+				PassQueryString:      &passQueryString,
+				MirrorURL:            ruleConf.GetString("redirect.mirror-url"),
+				Protocol:             ruleConf.GetString("redirect.protocol"),
+				HostName:             ruleConf.GetString("redirect.host-name"),
+				ReplaceKeyWith:       ruleConf.GetString("redirect.replace-key-with"),
+				ReplaceKeyPrefixWith: ruleConf.GetString("redirect.replace-key-prefix-with"),
+			},
+		}
+
+		if redirectType == "Mirror" && len(rule.Redirect.MirrorURL) == 0 {
+			err = fmt.Errorf("the routing rule of %s in bucket %s is Mirror, but redirect.mirror-url is empty", ruleName, bucketName)
+			return
+		}
+
+		if redirectType == "External" || redirectType == "AliCDN" {
+			rule.Redirect.HttpRedirectCode = int(ruleConf.GetInt32("redirect.http-redirect-code", 302))
+		}
+
+		websiteXML.RoutingRules = append(websiteXML.RoutingRules, rule)
+	}
+
+	return
+}
+
+func (p *Aliyun) bindOSSWebsiteDomain(bucketName string, domainConf config.Configuration) (err error) {
+
+	domainName := domainConf.GetString("domain-name")
+	rr := domainConf.GetString("rr")
+
+	if len(domainName) == 0 || len(rr) == 0 {
+		err = fmt.Errorf("the website domain config of bucket %s's domain-name or rr is empty", bucketName)
+		return
+	}
+
+	cname := domainName
+
+	if rr != "@" {
+		cname = rr + "." + domainName
+	}
+
+	err = p.OSSClient().PutBucketCname(bucketName, cname)
+	if err != nil {
+		err = fmt.Errorf("bind cname '%s' to bucket '%s' failure: %s", cname, bucketName, err.Error())
+		return
+	}
+
+	logrus.WithField("code", p.Code).
+		WithField("bucket", bucketName).
+		WithField("cname", cname).
+		Infoln("bucket cname binded")
+
+	if !domainConf.GetBoolean("dns-record", true) {
+		return
+	}
+
+	req := alidns.CreateAddDomainRecordRequest()
+
+	req.DomainName = domainName
+	req.RR = rr
+	req.Type = "CNAME"
+	req.Value = p.ossBucketEndpoint(bucketName)
+	req.TTL = requests.NewInteger(int(domainConf.GetInt32("ttl", 600)))
+	req.Line = domainConf.GetString("line", "default")
+
+	err = p.addDomainRecord(req)
+
+	return
+}
+
+func (p *Aliyun) ossBucketEndpoint(bucketName string) string {
+	return fmt.Sprintf("%s.oss-%s.aliyuncs.com", bucketName, p.Region)
+}
@@ -0,0 +1,393 @@
+package aliyun
+
+import (
+	"fmt"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/pvtz"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/vpc"
+
+	"github.com/sirupsen/logrus"
+)
+
+func (p *Aliyun) listPrivateZones(keyword string) (zones []pvtz.Zone, err error) {
+
+	pageNumber := 1
+	pageSize := 100
+
+	for {
+		req := pvtz.CreateDescribeZonesRequest()
+
+		req.Keyword = keyword
+		req.PageNumber = requests.NewInteger(pageNumber)
+		req.PageSize = requests.NewInteger(pageSize)
+
+		var resp *pvtz.DescribeZonesResponse
+		resp, err = p.PVTZClient().DescribeZones(req)
+		if err != nil {
+			return
+		}
+
+		zones = append(zones, resp.Zones.Zone...)
+
+		if len(resp.Zones.Zone) < pageSize {
+			break
+		}
+
+		pageNumber++
+	}
+
+	return
+}
+
+func (p *Aliyun) FindPrivateZone(zoneName string) (zone *pvtz.Zone, err error) {
+
+	zones, err := p.listPrivateZones(zoneName)
+	if err != nil {
+		return
+	}
+
+	for i, z := range zones {
+		if z.ZoneName == zoneName &&
+			p.isSignd(z.Remark) {
+			zone = &zones[i]
+			return
+		}
+	}
+
+	return
+}
+
+func (p *Aliyun) CreatePrivateZones() (err error) {
+
+	zonesConf := p.Config.GetConfig("aliyun.pvtz")
+
+	if zonesConf.IsEmpty() {
+		return
+	}
+
+	for _, name := range zonesConf.Keys() {
+
+		zoneConf := zonesConf.GetConfig(name)
+
+		zoneName := zoneConf.GetString("zone-name", name)
+
+		var zone *pvtz.Zone
+		zone, err = p.FindPrivateZone(zoneName)
+		if err != nil {
+			return
+		}
+
+		if zone != nil {
+			logrus.WithField("CODE", p.Code).
+				WithField("PVTZ-ZONE-NAME", zoneName).
+				WithField("PVTZ-ZONE-ID", zone.ZoneId).Infoln("Private zone already created")
+			continue
+		}
+
+		req := pvtz.CreateAddZoneRequest()
+		req.ZoneName = zoneName
+
+		var resp *pvtz.AddZoneResponse
+		resp, err = p.PVTZClient().AddZone(req)
+		if err != nil {
+			return
+		}
+
+		remarkReq := pvtz.CreateUpdateZoneRemarkRequest()
+		remarkReq.ZoneId = resp.ZoneId
+		remarkReq.Remark = p.signWithCode(zoneConf.GetString("remark"))
+
+		_, err = p.PVTZClient().UpdateZoneRemark(remarkReq)
+		if err != nil {
+			return
+		}
+
+		logrus.WithField("CODE", p.Code).
+			WithField("PVTZ-ZONE-NAME", zoneName).
+			WithField("PVTZ-ZONE-ID", resp.ZoneId).Infoln("Private zone created")
+	}
+
+	return
+}
+
+func (p *Aliyun) BindPrivateZoneVPCs() (err error) {
+
+	zonesConf := p.Config.GetConfig("aliyun.pvtz")
+
+	if zonesConf.IsEmpty() {
+		return
+	}
+
+	for _, name := range zonesConf.Keys() {
+
+		zoneConf := zonesConf.GetConfig(name)
+
+		zoneName := zoneConf.GetString("zone-name", name)
+
+		var zone *pvtz.Zone
+		zone, err = p.FindPrivateZone(zoneName)
+		if err != nil {
+			return
+		}
+
+		if zone == nil {
+			err = fmt.Errorf("private zone of %s not exist", zoneName)
+			return
+		}
+
+		var vpcs []pvtz.BindZoneVpcVpcs
+
+		for _, vpcName := range zoneConf.GetStringList("vpcs") {
+
+			var vpcInst *vpc.Vpc
+			vpcInst, err = p.FindVPC(vpcName)
+			if err != nil {
+				return
+			}
+
+			if vpcInst == nil {
+				err = fmt.Errorf("private zone config of %s's vpc %s is not found at aliyun", zoneName, vpcName)
+				return
+			}
+
+			vpcs = append(vpcs, pvtz.BindZoneVpcVpcs{RegionId: p.Region, VpcId: vpcInst.VpcId})
+		}
+
+		req := pvtz.CreateBindZoneVpcRequest()
+		req.ZoneId = zone.ZoneId
+		req.Vpcs = &vpcs
+
+		_, err = p.PVTZClient().BindZoneVpc(req)
+		if err != nil {
+			return
+		}
+
+		logrus.WithField("CODE", p.Code).
+			WithField("PVTZ-ZONE-NAME", zoneName).
+			WithField("PVTZ-ZONE-ID", zone.ZoneId).
+			WithField("VPCS", zoneConf.GetStringList("vpcs")).Infoln("Private zone vpc binded")
+	}
+
+	return
+}
+
+func (p *Aliyun) listPrivateZoneRecords(zoneId string) (records []pvtz.Record, err error) {
+
+	pageNumber := 1
+	pageSize := 100
+
+	for {
+		req := pvtz.CreateDescribeZoneRecordsRequest()
+
+		req.ZoneId = zoneId
+		req.PageNumber = requests.NewInteger(pageNumber)
+		req.PageSize = requests.NewInteger(pageSize)
+
+		var resp *pvtz.DescribeZoneRecordsResponse
+		resp, err = p.PVTZClient().DescribeZoneRecords(req)
+		if err != nil {
+			return
+		}
+
+		records = append(records, resp.Records.Record...)
+
+		if len(resp.Records.Record) < pageSize {
+			break
+		}
+
+		pageNumber++
+	}
+
+	return
+}
+
+func (p *Aliyun) SyncPrivateZoneRecords() (err error) {
+
+	zonesConf := p.Config.GetConfig("aliyun.pvtz")
+
+	if zonesConf.IsEmpty() {
+		return
+	}
+
+	valueCache := &dnsValueCache{}
+
+	for _, name := range zonesConf.Keys() {
+
+		zoneConf := zonesConf.GetConfig(name)
+
+		zoneName := zoneConf.GetString("zone-name", name)
+
+		recordsConf := zoneConf.GetConfig("records")
+
+		if recordsConf.IsEmpty() {
+			continue
+		}
+
+		var zone *pvtz.Zone
+		zone, err = p.FindPrivateZone(zoneName)
+		if err != nil {
+			return
+		}
+
+		if zone == nil {
+			err = fmt.Errorf("private zone of %s not exist", zoneName)
+			return
+		}
+
+		var records []pvtz.Record
+		records, err = p.listPrivateZoneRecords(zone.ZoneId)
+		if err != nil {
+			return
+		}
+
+		for _, recordName := range recordsConf.Keys() {
+
+			recordConf := recordsConf.GetConfig(recordName)
+
+			rr := recordConf.GetString("rr", recordName)
+			typ := recordConf.GetString("type", "A")
+			ttl := int(recordConf.GetInt32("ttl", 60))
+			priority := int(recordConf.GetInt32("priority", 10))
+
+			var value string
+			value, err = p.resolveDNSValue(valueCache, recordConf.GetString("value"))
+			if err != nil {
+				return
+			}
+
+			if len(value) == 0 {
+				err = fmt.Errorf("private zone record config of %s.%s's value is empty", zoneName, recordName)
+				return
+			}
+
+			var matched []pvtz.Record
+			for _, record := range records {
+				if record.Rr == rr && record.Type == typ {
+					matched = append(matched, record)
+				}
+			}
+
+			if len(matched) > 1 {
+				err = fmt.Errorf("more than one private zone record matched for %s.%s, rr: %s, type: %s", zoneName, recordName, rr, typ)
+				return
+			}
+
+			if len(matched) == 0 {
+
+				req := pvtz.CreateAddZoneRecordRequest()
+
+				req.ZoneId = zone.ZoneId
+				req.Rr = rr
+				req.Type = typ
+				req.Value = value
+				req.Ttl = requests.NewInteger(ttl)
+				req.Priority = requests.NewInteger(priority)
+
+				_, err = p.PVTZClient().AddZoneRecord(req)
+				if err != nil {
+					return
+				}
+
+				logrus.WithField("CODE", p.Code).
+					WithField("PVTZ-ZONE-NAME", zoneName).
+					WithField("RR", rr).
+					WithField("TYPE", typ).
+					WithField("VALUE", value).Infoln("Private zone record created")
+
+				continue
+			}
+
+			record := matched[0]
+
+			if record.Value == value && int(record.Ttl) == ttl {
+				continue
+			}
+
+			req := pvtz.CreateUpdateZoneRecordRequest()
+
+			req.RecordId = requests.NewInteger64(int64(record.RecordId))
+			req.Rr = rr
+			req.Type = typ
+			req.Value = value
+			req.Ttl = requests.NewInteger(ttl)
+			req.Priority = requests.NewInteger(priority)
+
+			_, err = p.PVTZClient().UpdateZoneRecord(req)
+			if err != nil {
+				return
+			}
+
+			logrus.WithField("CODE", p.Code).
+				WithField("PVTZ-ZONE-NAME", zoneName).
+				WithField("RR", rr).
+				WithField("TYPE", typ).
+				WithField("VALUE", value).Infoln("Private zone record updated")
+		}
+	}
+
+	return
+}
+
+func (p *Aliyun) DeletePrivateZones() (err error) {
+
+	zonesConf := p.Config.GetConfig("aliyun.pvtz")
+
+	if zonesConf.IsEmpty() {
+		return
+	}
+
+	for _, name := range zonesConf.Keys() {
+
+		zoneName := zonesConf.GetString(name+".zone-name", name)
+
+		var zone *pvtz.Zone
+		zone, err = p.FindPrivateZone(zoneName)
+		if err != nil {
+			return
+		}
+
+		if zone == nil {
+			continue
+		}
+
+		unbindReq := pvtz.CreateBindZoneVpcRequest()
+		unbindReq.ZoneId = zone.ZoneId
+		unbindReq.Vpcs = &[]pvtz.BindZoneVpcVpcs{}
+
+		_, err = p.PVTZClient().BindZoneVpc(unbindReq)
+		if err != nil {
+			return
+		}
+
+		var records []pvtz.Record
+		records, err = p.listPrivateZoneRecords(zone.ZoneId)
+		if err != nil {
+			return
+		}
+
+		for _, record := range records {
+			req := pvtz.CreateDeleteZoneRecordRequest()
+			req.RecordId = requests.NewInteger64(int64(record.RecordId))
+
+			_, err = p.PVTZClient().DeleteZoneRecord(req)
+			if err != nil {
+				return
+			}
+		}
+
+		req := pvtz.CreateDeleteZoneRequest()
+		req.ZoneId = zone.ZoneId
+
+		_, err = p.PVTZClient().DeleteZone(req)
+		if err != nil {
+			return
+		}
+
+		logrus.WithField("CODE", p.Code).
+			WithField("PVTZ-ZONE-NAME", zoneName).
+			WithField("PVTZ-ZONE-ID", zone.ZoneId).Infoln("Private zone deleted")
+	}
+
+	return
+}
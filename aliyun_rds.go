@@ -1,8 +1,10 @@
 package aliyun
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -45,7 +47,8 @@ func (p *Aliyun) listRDSInstance(tags map[string]string) (resp *rds.DescribeDBIn
 }
 
 type DBInstanceAttribute struct {
-	Name string
+	Name        string
+	PrimaryName string `json:",omitempty"`
 	rds.DBInstanceAttribute
 	Tags map[string]string
 }
@@ -105,6 +108,8 @@ func (p *Aliyun) DescribeRDSInstancesAttr() (attr []DBInstanceAttribute, err err
 			item.Name = attr.DBInstanceDescription
 		}
 
+		item.PrimaryName = mapTags["primary"]
+
 		ret = append(ret, item)
 	}
 
@@ -201,12 +206,30 @@ func (p *Aliyun) CreateRDSInstances() (createResps []*rds.CreateDBInstanceRespon
 			return
 		}
 
+		profile, known := lookupRDSEngineProfile(engine)
+		if !known {
+			logrus.WithField("CODE", p.Code).
+				WithField("RDS-DBINSTANCE-NAME", rdsName).
+				WithField("RDS-ENGINE", engine).Warnln("RDS engine profile not found, the defaults and validation are skipped")
+		}
+
+		instStorage := int(rdsConf.GetInt32("instance-storage", int32(profile.MinStorage)))
+
 		arg.Engine = engine
-		arg.EngineVersion = rdsConf.GetString("engine-version", "5.6")
+		arg.EngineVersion = rdsConf.GetString("engine-version", profile.DefaultEngineVersion())
 		arg.PayType = rdsConf.GetString("pay-type", "Postpaid")
 
-		arg.DBInstanceClass = rdsConf.GetString("instance-class", "rds.mys2.small")
-		arg.DBInstanceStorage = requests.NewInteger(int(rdsConf.GetInt32("instance-storage", 5)))
+		arg.DBInstanceClass = rdsConf.GetString("instance-class", profile.DefaultInstanceClass)
+		arg.DBInstanceStorage = requests.NewInteger(instStorage)
+
+		if known {
+			if e := profile.Validate(arg.EngineVersion, arg.DBInstanceClass, instStorage); e != nil {
+				logrus.WithField("CODE", p.Code).
+					WithField("RDS-DBINSTANCE-NAME", rdsName).
+					WithField("RDS-ENGINE", engine).Warnln(e.Error())
+			}
+		}
+
 		arg.DBInstanceNetType = rdsConf.GetString("instance-net-type", "Internet")
 		arg.DBInstanceDescription = rdsName
 		arg.InstanceNetworkType = rdsConf.GetString("instance-network-type", "VPC")
@@ -239,28 +262,7 @@ func (p *Aliyun) CreateRDSInstances() (createResps []*rds.CreateDBInstanceRespon
 			return
 		}
 
-		addTagsReq := rds.CreateAddTagsToResourceRequest()
-		addTagsReq.RegionId = string(p.Region)
-
-		addTagsReq.DBInstanceId = resp.DBInstanceId
-
-		addTagsReq.Tag1Key = "code"
-		addTagsReq.Tag1Value = p.Code
-
-		addTagsReq.Tag2Key = "creator"
-		addTagsReq.Tag2Value = "go-flow"
-
-		addTagsReq.Tag3Key = "name"
-		addTagsReq.Tag3Value = arg.Name
-
-		var oRdsClient *rds.Client
-		oRdsClient, err = rds.NewClientWithAccessKey(string(p.Region), p.AccessKeyId, p.AccessKeySecret)
-		if err != nil {
-			return
-		}
-
-		_, err = oRdsClient.AddTagsToResource(addTagsReq)
-
+		err = p.addRDSInstanceTags(resp.DBInstanceId, arg.Name)
 		if err != nil {
 			return
 		}
@@ -313,10 +315,16 @@ func (p *Aliyun) CreateRDSDbAccount() (err error) {
 			continue
 		}
 
+		// databases should be exist before grant privileges
+		err = p.createRDSInstanceDatabases(rdsName, engine, dbIns.DBInstanceId, rdsConf.GetConfig("databases"))
+		if err != nil {
+			return
+		}
+
 		accountsConf := rdsConf.GetConfig("accounts")
 
 		if accountsConf.IsEmpty() {
-			return
+			continue
 		}
 
 		var accountsResp *rds.DescribeAccountsResponse
@@ -336,10 +344,13 @@ func (p *Aliyun) CreateRDSDbAccount() (err error) {
 			existAccounts[account.AccountName] = account
 		}
 
-		if engine == "PostgreSQL" {
-			if len(accountsConf.Keys()) > 1 {
-				err = fmt.Errorf("the db of [%s]'s instance type is PostgreSQL, it only can create one admin account", rdsName)
-				return
+		profile, known := lookupRDSEngineProfile(engine)
+
+		superAccounts := 0
+
+		for _, account := range existAccounts {
+			if account.AccountType == "Super" {
+				superAccounts++
 			}
 		}
 
@@ -349,17 +360,37 @@ func (p *Aliyun) CreateRDSDbAccount() (err error) {
 
 			accountName = accountsConf.GetString("account", accountName)
 
-			_, exist := existAccounts[accountName]
+			accountType := accountConf.GetString("type", profile.DefaultAccountType)
+
+			existAccount, exist := existAccounts[accountName]
+
+			if exist {
+				accountType = existAccount.AccountType
+			}
 
 			if !exist {
 
+				if accountType == "Super" {
+					if known && superAccounts >= profile.MaxSuperAccounts {
+						err = fmt.Errorf("the db of [%s]'s instance type is %s, it only can create %d super account", rdsName, engine, profile.MaxSuperAccounts)
+						return
+					}
+					superAccounts++
+				}
+
 				createAccountArgs := rds.CreateCreateAccountRequest()
 
 				createAccountArgs.DBInstanceId = dbIns.DBInstanceId
 				createAccountArgs.AccountName = accountName
 				createAccountArgs.AccountPassword = accountConf.GetString("password")
-				createAccountArgs.AccountDescription = accountConf.GetString("description")
-				createAccountArgs.AccountType = accountConf.GetString("type", "Normal")
+				// the description should start with a letter, and it is marked with code for revoking
+				description := strings.TrimSpace(accountConf.GetString("description"))
+				if len(description) == 0 {
+					description = "go-flow account"
+				}
+
+				createAccountArgs.AccountDescription = p.signWithCode(description)
+				createAccountArgs.AccountType = accountType
 
 				_, err = p.RDSClient().CreateAccount(createAccountArgs)
 
@@ -370,25 +401,28 @@ func (p *Aliyun) CreateRDSDbAccount() (err error) {
 
 			privilegeConf := accountConf.GetConfig("databases")
 
-			if engine == "PostgreSQL" {
-				if len(privilegeConf.Keys()) > 0 {
-					err = fmt.Errorf("the db of [%s]'s instance type is PostgreSQL,it could not grant privilege", rdsName)
-					return
-				}
-			}
-
 			if privilegeConf.IsEmpty() {
 				continue
 			}
 
 			for _, dbName := range privilegeConf.Keys() {
 
+				privilege := privilegeConf.GetString(dbName+".privilege", profile.DefaultPrivilege)
+
+				if known {
+					err = profile.ValidatePrivilege(accountType, privilege)
+					if err != nil {
+						err = fmt.Errorf("grant privilege of database %s to account %s in rds %s failure: %s", dbName, accountName, rdsName, err.Error())
+						return
+					}
+				}
+
 				grantArgs := rds.CreateGrantAccountPrivilegeRequest()
 
 				grantArgs.DBInstanceId = dbIns.DBInstanceId
 				grantArgs.AccountName = accountName
 				grantArgs.DBName = dbName
-				grantArgs.AccountPrivilege = privilegeConf.GetString(dbName+".privilege", "ReadWrite")
+				grantArgs.AccountPrivilege = privilege
 
 				_, err = p.RDSClient().GrantAccountPrivilege(grantArgs)
 				if err != nil {
@@ -471,33 +505,97 @@ func (p *Aliyun) DeleteRDSInstances() (err error) {
 	return
 }
 
-func (p *Aliyun) AllocateInstancePublicConnection() (err error) {
+type rdsPublicRelease struct {
+	Name         string
+	DBInstanceId string
+	Deadline     time.Time
+}
+
+// AllocateInstancePublicConnection allocates public connection for the instances which
+// aliyun.rds.<name>.public.enabled is true, if public.release-after (minutes) is set,
+// it will block until the deadline or ctx canceled, and release the connection
+func (p *Aliyun) AllocateInstancePublicConnection(ctx context.Context) (err error) {
 	rdsInst, err := p.DescribeRDSInstancesAttr()
 	if err != nil {
 		return
 	}
 
+	var releases []rdsPublicRelease
+
 	for _, inst := range rdsInst {
+
+		publicConf := p.Config.GetConfig(fmt.Sprintf("aliyun.rds.%s.public", inst.Name))
+
+		if !publicConf.GetBoolean("enabled", false) {
+			continue
+		}
+
 		req := rds.CreateAllocateInstancePublicConnectionRequest()
 
 		req.DBInstanceId = inst.DBInstanceId
-		req.Port = inst.Port
-		req.ConnectionStringPrefix = fmt.Sprintf("o-%s", inst.DBInstanceId)
+		req.Port = publicConf.GetString("port", inst.Port)
+		req.ConnectionStringPrefix = publicConf.GetString("prefix", fmt.Sprintf("o-%s", inst.DBInstanceId))
 
 		_, err = p.RDSClient().AllocateInstancePublicConnection(req)
 
 		if IsAliErrCode(err, "NetTypeExists") {
 			err = nil
-			continue
+			logrus.WithField("CODE", p.Code).WithField("RDS-DBINSTANCE-NAME", inst.Name).Infoln("Db instance public connection already allocated")
+		} else if err != nil {
+			err = fmt.Errorf("allocate public connection of rds '%s' failure: %s", inst.Name, err.Error())
+			return
+		} else {
+			logrus.WithField("CODE", p.Code).
+				WithField("RDS-DBINSTANCE-NAME", inst.Name).
+				WithField("RDS-CONN-PREFIX", req.ConnectionStringPrefix).
+				WithField("RDS-PORT", req.Port).Infoln("Db instance public connection allocated")
+
+			err = p.WaitForDBInstance(inst.DBInstanceId, "Running", 60*10)
+			if err != nil {
+				return
+			}
+
+			// only the connection allocated by this call is released after release-after minutes
+			if releaseAfter := publicConf.GetInt32("release-after", 0); releaseAfter > 0 {
+				releases = append(releases, rdsPublicRelease{
+					Name:         inst.Name,
+					DBInstanceId: inst.DBInstanceId,
+					Deadline:     time.Now().Add(time.Duration(releaseAfter) * time.Minute),
+				})
+			}
+		}
+	}
+
+	sort.Slice(releases, func(i, j int) bool { return releases[i].Deadline.Before(releases[j].Deadline) })
+
+	for _, release := range releases {
+
+		logrus.WithField("CODE", p.Code).
+			WithField("RDS-DBINSTANCE-NAME", release.Name).
+			WithField("DEADLINE", release.Deadline.Format(time.RFC3339)).Infoln("Db instance public connection will be released at deadline")
+
+		timer := time.NewTimer(time.Until(release.Deadline))
+
+		select {
+		case <-ctx.Done():
+			{
+				timer.Stop()
+				err = fmt.Errorf("release public connection of rds '%s' canceled before deadline %s", release.Name, release.Deadline.Format(time.RFC3339))
+				return
+			}
+		case <-timer.C:
 		}
 
+		err = p.releaseInstancePublicConnection(release.Name, release.DBInstanceId)
 		if err != nil {
 			return
 		}
 	}
+
 	return
 }
 
+// ReleaseInstancePublicConnection releases public connection of all instances of code
 func (p *Aliyun) ReleaseInstancePublicConnection() (err error) {
 	rdsInst, err := p.DescribeRDSInstancesAttr()
 	if err != nil {
@@ -505,24 +603,104 @@ func (p *Aliyun) ReleaseInstancePublicConnection() (err error) {
 	}
 
 	for _, inst := range rdsInst {
+
+		err = p.releaseInstancePublicConnection(inst.Name, inst.DBInstanceId)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (p *Aliyun) releaseInstancePublicConnection(rdsName, dbInstanceId string) (err error) {
+
+	netReq := rds.CreateDescribeDBInstanceNetInfoRequest()
+	netReq.DBInstanceId = dbInstanceId
+
+	netResp, err := p.RDSClient().DescribeDBInstanceNetInfo(netReq)
+	if err != nil {
+		return
+	}
+
+	released := false
+
+	for _, netInfo := range netResp.DBInstanceNetInfos.DBInstanceNetInfo {
+
+		if netInfo.IPType != "Public" {
+			continue
+		}
+
 		req := rds.CreateReleaseInstancePublicConnectionRequest()
 
-		req.DBInstanceId = inst.DBInstanceId
-		req.CurrentConnectionString = fmt.Sprintf("o-%s", inst.DBInstanceId)
+		req.DBInstanceId = dbInstanceId
+		req.CurrentConnectionString = netInfo.ConnectionString
 
 		_, err = p.RDSClient().ReleaseInstancePublicConnection(req)
-
 		if err != nil {
+			err = fmt.Errorf("release public connection '%s' of rds '%s' failure: %s", netInfo.ConnectionString, rdsName, err.Error())
 			return
 		}
+
+		logrus.WithField("CODE", p.Code).
+			WithField("RDS-DBINSTANCE-NAME", rdsName).
+			WithField("RDS-CONN-STR", netInfo.ConnectionString).Infoln("Db instance public connection released")
+
+		released = true
 	}
 
+	if !released {
+		logrus.WithField("CODE", p.Code).WithField("RDS-DBINSTANCE-NAME", rdsName).Infoln("Db instance has no public connection")
+		return
+	}
+
+	err = p.WaitForDBInstance(dbInstanceId, "Running", 60*10)
+
+	return
+}
+
+func (p *Aliyun) addRDSInstanceTags(dbInstanceId, name string, tags ...Tag) (err error) {
+
+	addTagsReq := rds.CreateAddTagsToResourceRequest()
+	addTagsReq.RegionId = string(p.Region)
+
+	addTagsReq.DBInstanceId = dbInstanceId
+
+	addTagsReq.Tag1Key = "code"
+	addTagsReq.Tag1Value = p.Code
+
+	addTagsReq.Tag2Key = "creator"
+	addTagsReq.Tag2Value = "go-flow"
+
+	addTagsReq.Tag3Key = "name"
+	addTagsReq.Tag3Value = name
+
+	for i, tag := range tags {
+		switch i {
+		case 0:
+			addTagsReq.Tag4Key = tag.Key
+			addTagsReq.Tag4Value = tag.Value
+		case 1:
+			addTagsReq.Tag5Key = tag.Key
+			addTagsReq.Tag5Value = tag.Value
+		}
+	}
+
+	var oRdsClient *rds.Client
+	oRdsClient, err = rds.NewClientWithAccessKey(string(p.Region), p.AccessKeyId, p.AccessKeySecret)
+	if err != nil {
+		return
+	}
+
+	_, err = oRdsClient.AddTagsToResource(addTagsReq)
+
 	return
 }
 
 type RDSDBInstanceNetInfo struct {
 	InstanceId   string
 	InstanceName string
+	PrimaryName  string `json:",omitempty"`
 	NetInfo      []rds.DBInstanceNetInfo
 	Tags         map[string]string
 }
@@ -573,6 +751,7 @@ func (p *Aliyun) DescribeDBInstanceNetInfo() (instNetInfos []RDSDBInstanceNetInf
 		ret = append(ret, RDSDBInstanceNetInfo{
 			InstanceId:   inst.DBInstanceId,
 			InstanceName: name,
+			PrimaryName:  tags["primary"],
 			NetInfo:      resp.DBInstanceNetInfos.DBInstanceNetInfo,
 			Tags:         tags,
 		})
@@ -603,40 +782,137 @@ func (p *Aliyun) getInstanceTags(dbInstanceId string) map[string]string {
 	return mapTags
 }
 
-// WaitForInstance waits for instance to given status
+// rdsTerminalStatus is the status of db instance which would not reach to others while waiting
+var rdsTerminalStatus = []string{"Deleting", "Failover"}
+
+func (p *Aliyun) rdsInstanceStatus(instanceId string) (status string, err error) {
+
+	args := rds.CreateDescribeDBInstancesRequest()
+
+	args.DBInstanceId = instanceId
+
+	resp, err := p.RDSClient().DescribeDBInstances(args)
+	if err != nil {
+		return
+	}
+
+	if !resp.IsSuccess() {
+		err = fmt.Errorf("describe db instances failure")
+		return
+	}
+
+	if len(resp.Items.DBInstance) < 1 {
+		status = WaitNotFound
+		return
+	}
+
+	status = resp.Items.DBInstance[0].DBInstanceStatus
+
+	return
+}
+
+// WaitForDBInstance waits for instance to given status
 func (p *Aliyun) WaitForDBInstance(instanceId string, status string, timeout int) error {
+	return p.WaitForDBInstanceContext(context.Background(), instanceId, status, timeout)
+}
+
+func (p *Aliyun) WaitForDBInstanceContext(ctx context.Context, instanceId string, status string, timeout int) error {
+
 	if timeout <= 0 {
 		timeout = 120
 	}
-	for {
 
-		args := rds.CreateDescribeDBInstancesRequest()
+	var terminal []string
+	if status != WaitNotFound {
+		for _, s := range rdsTerminalStatus {
+			if s != status {
+				terminal = append(terminal, s)
+			}
+		}
+	}
 
-		args.DBInstanceId = instanceId
+	waiter := p.newWaiter(fmt.Sprintf("db instance %s to %s", instanceId, status), time.Duration(timeout)*time.Second, []string{status}, terminal...)
+	waiter.Fields["RDS-DBINSTANCE-ID"] = instanceId
 
-		resp, err := p.RDSClient().DescribeDBInstances(args)
+	return waiter.Wait(ctx, func() (string, error) {
+		return p.rdsInstanceStatus(instanceId)
+	})
+}
 
-		if err != nil {
-			return nil
-		}
+// rdsTransitionLeaveTimeout is the default time waiting for the instance to leave Running after the operation accepted,
+// the operation is regarded as finished already while the instance is still Running after it
+const rdsTransitionLeaveTimeout = 5 * time.Minute
 
-		if !resp.IsSuccess() {
-			err = fmt.Errorf("describe db instances failure")
-			return err
+// waitForDBInstanceTransition waits for the instance to leave Running after the operation accepted,
+// and then waits for it back to Running, the instance still reports Running right after the operation call.
+// The no-op or fast operation may never leave Running in leaveTimeout, it is not an error
+func (p *Aliyun) waitForDBInstanceTransition(ctx context.Context, instanceId, transition string, leaveTimeout time.Duration, timeout int) (err error) {
+
+	deadline := time.Now().Add(leaveTimeout)
+
+	leaving := p.newWaiter(fmt.Sprintf("db instance %s to start %s", instanceId, transition), leaveTimeout, []string{transition, "Running"}, rdsTerminalStatus...)
+	leaving.Fields["RDS-DBINSTANCE-ID"] = instanceId
+
+	// the leave window is not overridden by aliyun.wait.timeout, the deadline in status func ends it,
+	// and the waiter timeout is only the safety net
+	leaving.Timeout = 2 * leaveTimeout
+
+	err = leaving.Wait(ctx, func() (string, error) {
+		status, e := p.rdsInstanceStatus(instanceId)
+		if e != nil || stringInSlice(status, rdsTerminalStatus) {
+			return status, e
 		}
 
-		if len(resp.Items.DBInstance) < 1 {
-			break
+		if status != "Running" {
+			return transition, nil
 		}
 
-		instance := resp.Items.DBInstance[0]
-		if instance.DBInstanceStatus == status {
-			break
+		if time.Now().After(deadline) {
+			return "Running", nil
 		}
 
-		timeout = timeout - 5
-		time.Sleep(5 * time.Second)
+		return "Running, waiting for " + transition, nil
+	})
 
+	if err != nil {
+		return
 	}
-	return nil
+
+	err = p.WaitForDBInstanceContext(ctx, instanceId, "Running", timeout)
+
+	return
+}
+
+// WaitForDBInstances waits for all instances of code to given status concurrently
+func (p *Aliyun) WaitForDBInstances(ctx context.Context, status string, timeout int) (err error) {
+
+	resp, err := p.listRDSInstance(nil)
+	if err != nil {
+		return
+	}
+
+	var waits []func(ctx context.Context) error
+
+	for _, inst := range resp.Items.DBInstance {
+
+		instId, name := inst.DBInstanceId, inst.DBInstanceDescription
+
+		waits = append(waits, func(ctx context.Context) error {
+
+			logrus.WithField("CODE", p.Code).WithField("RDS-DBINSTANCE-ID", instId).WithField("RDS-DBINSTANCE-NAME", name).Infoln("Waiting db instance")
+
+			e := p.WaitForDBInstanceContext(ctx, instId, status, timeout)
+			if e != nil {
+				return fmt.Errorf("rds '%s': %s", name, e.Error())
+			}
+
+			logrus.WithField("CODE", p.Code).WithField("RDS-DBINSTANCE-ID", instId).WithField("RDS-DBINSTANCE-NAME", name).Infoln("Db instance is " + status)
+
+			return nil
+		})
+	}
+
+	err = p.waitAll(ctx, waits...)
+
+	return
 }
@@ -0,0 +1,238 @@
+package aliyun
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/rds"
+
+	"github.com/sirupsen/logrus"
+)
+
+type RDSAccountCredential struct {
+	InstanceId   string
+	InstanceName string
+	AccountName  string
+	Password     string
+}
+
+// RotateRDSAccountPasswords resets the passwords of accounts in config, the credentials already rotated
+// are returned together with the error if it fails halfway
+func (p *Aliyun) RotateRDSAccountPasswords() (credentials []RDSAccountCredential, err error) {
+
+	rdssConf := p.Config.GetConfig("aliyun.rds")
+
+	if rdssConf.IsEmpty() {
+		return
+	}
+
+	for _, rdsName := range rdssConf.Keys() {
+
+		rdsConf := rdssConf.GetConfig(rdsName)
+
+		accountsConf := rdsConf.GetConfig("accounts")
+		revokeAccounts := rdsConf.GetStringList("revoke-accounts")
+
+		if accountsConf.IsEmpty() && len(revokeAccounts) == 0 {
+			continue
+		}
+
+		engine := rdsConf.GetString("engine", "MySQL")
+
+		var dbIns *rds.DBInstance
+		dbIns, err = p.FindRDSInstance(engine, rdsConf.GetString("vpc-name"), rdsConf.GetString("vswitch-name"), rdsName)
+		if err != nil {
+			return
+		}
+
+		if dbIns == nil {
+			logrus.WithField("CODE", p.Code).WithField("DBINSTANCE-NAME", rdsName).Infoln("RDS Instance not exist")
+			continue
+		}
+
+		for _, accountKey := range accountsConf.Keys() {
+
+			accountConf := accountsConf.GetConfig(accountKey)
+
+			accountName := accountConf.GetString("account", accountKey)
+
+			var password, fnName string
+
+			envPrompName := fmt.Sprintf("rds.%s.accounts.%s.password", rdsName, accountKey)
+
+			password, fnName, err = p.tryInvokeEnvFunc(envPrompName, accountConf.GetString("rotate-password", "func://pwgen?len=24"))
+			if err != nil {
+				return
+			}
+
+			if len(fnName) == 0 {
+				err = fmt.Errorf("the rotate-password of rds account %s.%s should be an env func, e.g. func://pwgen?len=24", rdsName, accountKey)
+				return
+			}
+
+			req := rds.CreateResetAccountPasswordRequest()
+
+			req.DBInstanceId = dbIns.DBInstanceId
+			req.AccountName = accountName
+			req.AccountPassword = password
+
+			_, err = p.RDSClient().ResetAccountPassword(req)
+			if err != nil {
+				err = fmt.Errorf("reset password of account '%s' in rds '%s' failure: %s", accountName, rdsName, err.Error())
+				return
+			}
+
+			setENV(fmt.Sprintf("rds_db_%s_%s_password", rdsName, accountName), password)
+
+			credentials = append(credentials, RDSAccountCredential{
+				InstanceId:   dbIns.DBInstanceId,
+				InstanceName: rdsName,
+				AccountName:  accountName,
+				Password:     password,
+			})
+
+			logrus.WithField("CODE", p.Code).
+				WithField("FUNC", fnName).
+				WithField("RDS-DBINSTANCE-NAME", rdsName).
+				WithField("RDS-ACCOUNT", accountName).Infoln("Account password rotated")
+		}
+	}
+
+	return
+}
+
+// RevokeRDSRemovedAccounts revokes all privileges of the accounts which were created by this code but removed from config,
+// the instance without accounts config is skipped, and the accounts created by others are never touched.
+// The accounts created by this code are marked with [code] in description, the accounts created before the mark
+// was introduced could not be recognized, list them in aliyun.rds.<name>.revoke-accounts to revoke them explicitly
+func (p *Aliyun) RevokeRDSRemovedAccounts() (err error) {
+
+	rdssConf := p.Config.GetConfig("aliyun.rds")
+
+	if rdssConf.IsEmpty() {
+		return
+	}
+
+	for _, rdsName := range rdssConf.Keys() {
+
+		rdsConf := rdssConf.GetConfig(rdsName)
+
+		accountsConf := rdsConf.GetConfig("accounts")
+		revokeAccounts := rdsConf.GetStringList("revoke-accounts")
+
+		if accountsConf.IsEmpty() && len(revokeAccounts) == 0 {
+			continue
+		}
+
+		engine := rdsConf.GetString("engine", "MySQL")
+
+		var dbIns *rds.DBInstance
+		dbIns, err = p.FindRDSInstance(engine, rdsConf.GetString("vpc-name"), rdsConf.GetString("vswitch-name"), rdsName)
+		if err != nil {
+			return
+		}
+
+		if dbIns == nil {
+			continue
+		}
+
+		declared := map[string]bool{}
+
+		for _, accountKey := range accountsConf.Keys() {
+			declared[accountsConf.GetString(accountKey+".account", accountKey)] = true
+		}
+
+		for _, accountName := range revokeAccounts {
+			if declared[accountName] {
+				err = fmt.Errorf("account %s of rds %s is both declared in accounts and revoke-accounts", accountName, rdsName)
+				return
+			}
+		}
+
+		describeAccReq := rds.CreateDescribeAccountsRequest()
+		describeAccReq.DBInstanceId = dbIns.DBInstanceId
+
+		var accountsResp *rds.DescribeAccountsResponse
+		accountsResp, err = p.RDSClient().DescribeAccounts(describeAccReq)
+		if err != nil {
+			return
+		}
+
+		for _, account := range accountsResp.Accounts.DBInstanceAccount {
+
+			if declared[account.AccountName] {
+				continue
+			}
+
+			if !p.isSignd(account.AccountDescription) && !stringInSlice(account.AccountName, revokeAccounts) {
+				continue
+			}
+
+			for _, privilege := range account.DatabasePrivileges.DatabasePrivilege {
+
+				req := rds.CreateRevokeAccountPrivilegeRequest()
+
+				req.DBInstanceId = dbIns.DBInstanceId
+				req.AccountName = account.AccountName
+				req.DBName = privilege.DBName
+
+				_, err = p.RDSClient().RevokeAccountPrivilege(req)
+				if err != nil {
+					return
+				}
+
+				logrus.WithField("CODE", p.Code).
+					WithField("RDS-DBINSTANCE-NAME", rdsName).
+					WithField("RDS-ACCOUNT", account.AccountName).
+					WithField("RDS-DB-NAME", privilege.DBName).Infoln("Account privilege revoked")
+			}
+		}
+	}
+
+	return
+}
+
+func (p *Aliyun) outputEncryptKey() string {
+	return p.Config.GetString("aliyun.output-encrypt-key", os.Getenv("ENV_ALIYUN_OUTPUT_ENCRYPT_KEY"))
+}
+
+// encryptOutput encrypts data by AES-256-GCM with sha256 of key, the result is base64 of nonce and cipher text
+func encryptOutput(key string, data []byte) (ret []byte, err error) {
+
+	if len(key) == 0 {
+		err = fmt.Errorf("the output encrypt key is empty, please set it to config of aliyun.output-encrypt-key or env ${ENV_ALIYUN_OUTPUT_ENCRYPT_KEY}")
+		return
+	}
+
+	hashKey := sha256.Sum256([]byte(key))
+
+	block, err := aes.NewCipher(hashKey[:])
+	if err != nil {
+		return
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return
+	}
+
+	sealed := gcm.Seal(nonce, nonce, data, nil)
+
+	ret = make([]byte, base64.StdEncoding.EncodedLen(len(sealed)))
+	base64.StdEncoding.Encode(ret, sealed)
+
+	return
+}
@@ -0,0 +1,355 @@
+package aliyun
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/rds"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/vpc"
+
+	"github.com/sirupsen/logrus"
+)
+
+type RDSBackup struct {
+	InstanceId   string
+	InstanceName string
+	BackupJobId  string
+	BackupId     string
+}
+
+func (p *Aliyun) ModifyRDSBackupPolicy() (err error) {
+
+	rdssConf := p.Config.GetConfig("aliyun.rds")
+
+	if rdssConf.IsEmpty() {
+		return
+	}
+
+	for _, rdsName := range rdssConf.Keys() {
+
+		rdsConf := rdssConf.GetConfig(rdsName)
+
+		backupConf := rdsConf.GetConfig("backup")
+
+		if backupConf.IsEmpty() {
+			continue
+		}
+
+		engine := rdsConf.GetString("engine", "MySQL")
+
+		var dbIns *rds.DBInstance
+		dbIns, err = p.FindRDSInstance(engine, rdsConf.GetString("vpc-name"), rdsConf.GetString("vswitch-name"), rdsName)
+		if err != nil {
+			return
+		}
+
+		if dbIns == nil {
+			err = fmt.Errorf("rds instance of %s not exist", rdsName)
+			return
+		}
+
+		periods := backupConf.GetStringList("preferred-period")
+		if len(periods) == 0 {
+			periods = []string{"Monday", "Wednesday", "Friday", "Sunday"}
+		}
+
+		req := rds.CreateModifyBackupPolicyRequest()
+
+		req.DBInstanceId = dbIns.DBInstanceId
+		req.PreferredBackupTime = backupConf.GetString("preferred-time", "02:00Z-03:00Z")
+		req.PreferredBackupPeriod = strings.Join(periods, ",")
+		req.BackupRetentionPeriod = strconv.Itoa(int(backupConf.GetInt32("retention-period", 7)))
+
+		if backupConf.GetBoolean("log-backup", true) {
+			req.BackupLog = "Enable"
+			req.LogBackupRetentionPeriod = strconv.Itoa(int(backupConf.GetInt32("log-retention-period", 7)))
+		} else {
+			req.BackupLog = "Disabled"
+		}
+
+		_, err = p.RDSClient().ModifyBackupPolicy(req)
+		if err != nil {
+			err = fmt.Errorf("modify backup policy of rds '%s' failure: %s", rdsName, err.Error())
+			return
+		}
+
+		logrus.WithField("CODE", p.Code).
+			WithField("RDS-DBINSTANCE-NAME", rdsName).
+			WithField("RDS-BACKUP-TIME", req.PreferredBackupTime).
+			WithField("RDS-BACKUP-PERIOD", req.PreferredBackupPeriod).
+			WithField("RDS-BACKUP-RETENTION", req.BackupRetentionPeriod).
+			WithField("RDS-BACKUP-LOG", req.BackupLog).Infoln("Backup policy modified")
+	}
+
+	return
+}
+
+func (p *Aliyun) CreateRDSBackups() (backups []RDSBackup, err error) {
+
+	rdssConf := p.Config.GetConfig("aliyun.rds")
+
+	if rdssConf.IsEmpty() {
+		return
+	}
+
+	for _, rdsName := range rdssConf.Keys() {
+
+		rdsConf := rdssConf.GetConfig(rdsName)
+
+		engine := rdsConf.GetString("engine", "MySQL")
+
+		var dbIns *rds.DBInstance
+		dbIns, err = p.FindRDSInstance(engine, rdsConf.GetString("vpc-name"), rdsConf.GetString("vswitch-name"), rdsName)
+		if err != nil {
+			return
+		}
+
+		if dbIns == nil {
+			err = fmt.Errorf("rds instance of %s not exist", rdsName)
+			return
+		}
+
+		req := rds.CreateCreateBackupRequest()
+
+		req.DBInstanceId = dbIns.DBInstanceId
+		req.BackupMethod = rdsConf.GetString("backup.method", "Physical")
+		req.BackupType = rdsConf.GetString("backup.type", "FullBackup")
+
+		var resp *rds.CreateBackupResponse
+		resp, err = p.RDSClient().CreateBackup(req)
+		if err != nil {
+			err = fmt.Errorf("create backup of rds '%s' failure: %s", rdsName, err.Error())
+			return
+		}
+
+		logrus.WithField("CODE", p.Code).
+			WithField("RDS-DBINSTANCE-NAME", rdsName).
+			WithField("RDS-BACKUP-JOB-ID", resp.BackupJobId).Infoln("Backup job created, waiting for finished")
+
+		var backupId string
+		backupId, err = p.waitForRDSBackupJob(dbIns.DBInstanceId, resp.BackupJobId, int(rdsConf.GetInt32("backup.timeout", 3600)))
+		if err != nil {
+			return
+		}
+
+		logrus.WithField("CODE", p.Code).
+			WithField("RDS-DBINSTANCE-NAME", rdsName).
+			WithField("RDS-BACKUP-JOB-ID", resp.BackupJobId).
+			WithField("RDS-BACKUP-ID", backupId).Infoln("Backup finished")
+
+		backups = append(backups, RDSBackup{
+			InstanceId:   dbIns.DBInstanceId,
+			InstanceName: rdsName,
+			BackupJobId:  resp.BackupJobId,
+			BackupId:     backupId,
+		})
+	}
+
+	return
+}
+
+func (p *Aliyun) waitForRDSBackupJob(dbInstanceId, backupJobId string, timeout int) (backupId string, err error) {
+
+	if timeout <= 0 {
+		timeout = 3600
+	}
+
+	waiter := p.newWaiter(fmt.Sprintf("backup job %s of db instance %s to Finished", backupJobId, dbInstanceId), time.Duration(timeout)*time.Second, []string{"Finished"}, "Failed")
+	waiter.Fields["RDS-DBINSTANCE-ID"] = dbInstanceId
+	waiter.Fields["RDS-BACKUP-JOB-ID"] = backupJobId
+
+	err = waiter.Wait(context.Background(), func() (status string, e error) {
+
+		req := rds.CreateDescribeBackupTasksRequest()
+
+		req.DBInstanceId = dbInstanceId
+		req.BackupJobId = requests.Integer(backupJobId)
+
+		resp, e := p.RDSClient().DescribeBackupTasks(req)
+		if e != nil {
+			return
+		}
+
+		// the backup job may not be listed right after it created
+		if len(resp.Items.BackupJob) == 0 {
+			status = WaitNotFound
+			return
+		}
+
+		job := resp.Items.BackupJob[0]
+
+		status = job.BackupStatus
+		backupId = job.BackupId
+
+		return
+	})
+
+	if err != nil {
+		return
+	}
+
+	if len(backupId) == 0 {
+		backupId, err = p.latestRDSBackupId(dbInstanceId)
+	}
+
+	return
+}
+
+func (p *Aliyun) latestRDSBackupId(dbInstanceId string) (backupId string, err error) {
+
+	now := time.Now().UTC()
+
+	req := rds.CreateDescribeBackupsRequest()
+
+	req.DBInstanceId = dbInstanceId
+	req.BackupStatus = "Success"
+	req.StartTime = now.AddDate(0, 0, -30).Format("2006-01-02T15:04Z")
+	req.EndTime = now.Add(time.Hour).Format("2006-01-02T15:04Z")
+	req.PageSize = requests.NewInteger(100)
+
+	resp, err := p.RDSClient().DescribeBackups(req)
+	if err != nil {
+		return
+	}
+
+	latest := ""
+
+	for _, backup := range resp.Items.Backup {
+		if backup.BackupEndTime > latest {
+			latest = backup.BackupEndTime
+			backupId = backup.BackupId
+		}
+	}
+
+	if len(backupId) == 0 {
+		err = fmt.Errorf("no success backup found in db instance '%s'", dbInstanceId)
+		return
+	}
+
+	return
+}
+
+// CloneRDSInstances restores backup set or point in time of instance into new instances
+func (p *Aliyun) CloneRDSInstances() (err error) {
+
+	rdssConf := p.Config.GetConfig("aliyun.rds")
+
+	if rdssConf.IsEmpty() {
+		return
+	}
+
+	for _, rdsName := range rdssConf.Keys() {
+
+		rdsConf := rdssConf.GetConfig(rdsName)
+
+		clonesConf := rdsConf.GetConfig("clone")
+
+		if clonesConf.IsEmpty() {
+			continue
+		}
+
+		engine := rdsConf.GetString("engine", "MySQL")
+
+		var dbIns *rds.DBInstance
+		dbIns, err = p.FindRDSInstance(engine, rdsConf.GetString("vpc-name"), rdsConf.GetString("vswitch-name"), rdsName)
+		if err != nil {
+			return
+		}
+
+		if dbIns == nil {
+			err = fmt.Errorf("rds instance of %s not exist", rdsName)
+			return
+		}
+
+		for _, cloneName := range clonesConf.Keys() {
+
+			cloneConf := clonesConf.GetConfig(cloneName)
+
+			var exists *rds.DescribeDBInstancesResponse
+			exists, err = p.listRDSInstance(map[string]string{"name": cloneName})
+			if err != nil {
+				return
+			}
+
+			if len(exists.Items.DBInstance) > 0 {
+				logrus.WithField("CODE", p.Code).
+					WithField("RDS-DBINSTANCE-NAME", cloneName).
+					WithField("RDS-DBINSTANCE-ID", exists.Items.DBInstance[0].DBInstanceId).Infoln("RDS clone instance already created")
+				continue
+			}
+
+			vpcName := cloneConf.GetString("vpc-name", rdsConf.GetString("vpc-name"))
+			vSwitchName := cloneConf.GetString("vswitch-name")
+
+			if len(vSwitchName) == 0 {
+				err = fmt.Errorf("rds clone config of %s.%s's vswitch-name is empty", rdsName, cloneName)
+				return
+			}
+
+			var vSwitch *vpc.VSwitch
+			vSwitch, err = p.FindVSwitch(vpcName, vSwitchName)
+			if err != nil {
+				return
+			}
+
+			if vSwitch == nil {
+				err = fmt.Errorf("rds clone instance of %s vswitch is not found", cloneName)
+				return
+			}
+
+			req := rds.CreateCloneDBInstanceRequest()
+
+			req.DBInstanceId = dbIns.DBInstanceId
+			req.PayType = cloneConf.GetString("pay-type", "Postpaid")
+			req.DBInstanceClass = cloneConf.GetString("instance-class", dbIns.DBInstanceClass)
+			req.DBInstanceStorage = requests.NewInteger(int(cloneConf.GetInt32("instance-storage", rdsConf.GetInt32("instance-storage", 5))))
+			req.InstanceNetworkType = "VPC"
+			req.VPCId = vSwitch.VpcId
+			req.VSwitchId = vSwitch.VSwitchId
+			req.ZoneId = cloneConf.GetString("zone-id", vSwitch.ZoneId)
+
+			restoreType := "BackupSet"
+			restoreTime := cloneConf.GetString("restore-time")
+
+			if len(restoreTime) > 0 {
+				restoreType = "BackupTime"
+				req.RestoreTime = restoreTime
+			} else {
+				req.BackupId = cloneConf.GetString("backup-id", "latest")
+
+				if req.BackupId == "latest" {
+					req.BackupId, err = p.latestRDSBackupId(dbIns.DBInstanceId)
+					if err != nil {
+						return
+					}
+				}
+			}
+
+			var resp *rds.CloneDBInstanceResponse
+			resp, err = p.RDSClient().CloneDBInstance(req)
+			if err != nil {
+				err = fmt.Errorf("clone rds '%s' to '%s' failure: %s", rdsName, cloneName, err.Error())
+				return
+			}
+
+			err = p.addRDSInstanceTags(resp.DBInstanceId, cloneName)
+			if err != nil {
+				return
+			}
+
+			logrus.WithField("CODE", p.Code).
+				WithField("RDS-DBINSTANCE-NAME", rdsName).
+				WithField("RDS-CLONE-NAME", cloneName).
+				WithField("RDS-CLONE-ID", resp.DBInstanceId).
+				WithField("RDS-RESTORE-TYPE", restoreType).
+				WithField("RDS-BACKUP-ID", req.BackupId).
+				WithField("RDS-RESTORE-TIME", req.RestoreTime).Infoln("Db instance cloned")
+		}
+	}
+
+	return
+}
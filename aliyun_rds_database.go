@@ -0,0 +1,226 @@
+package aliyun
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/rds"
+	"github.com/gogap/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+func defaultRDSCharset(engine string) string {
+	profile, err := GetRDSEngineProfile(engine)
+	if err != nil {
+		return "utf8mb4"
+	}
+
+	return profile.Charset
+}
+
+func (p *Aliyun) listRDSDatabases(dbInstanceId string) (dbs map[string]rds.Database, err error) {
+
+	req := rds.CreateDescribeDatabasesRequest()
+	req.DBInstanceId = dbInstanceId
+
+	resp, err := p.RDSClient().DescribeDatabases(req)
+	if err != nil {
+		return
+	}
+
+	dbs = make(map[string]rds.Database)
+
+	for _, db := range resp.Databases.Database {
+		dbs[db.DBName] = db
+	}
+
+	return
+}
+
+func (p *Aliyun) createRDSInstanceDatabases(rdsName, engine, dbInstanceId string, databasesConf config.Configuration) (err error) {
+
+	if databasesConf.IsEmpty() {
+		return
+	}
+
+	existDBs, err := p.listRDSDatabases(dbInstanceId)
+	if err != nil {
+		return
+	}
+
+	var created []string
+
+	for _, dbName := range databasesConf.Keys() {
+
+		if _, exist := existDBs[dbName]; exist {
+			logrus.WithField("CODE", p.Code).
+				WithField("RDS-DBINSTANCE-NAME", rdsName).
+				WithField("RDS-DB-NAME", dbName).Debugln("Database already created")
+			continue
+		}
+
+		dbConf := databasesConf.GetConfig(dbName)
+
+		req := rds.CreateCreateDatabaseRequest()
+
+		req.DBInstanceId = dbInstanceId
+		req.DBName = dbName
+		req.CharacterSetName = dbConf.GetString("charset", defaultRDSCharset(engine))
+		req.DBDescription = dbConf.GetString("description")
+
+		_, err = p.RDSClient().CreateDatabase(req)
+		if err != nil {
+			err = fmt.Errorf("create database '%s' in rds instance '%s' failure: %s", dbName, rdsName, err.Error())
+			return
+		}
+
+		created = append(created, dbName)
+
+		logrus.WithField("CODE", p.Code).
+			WithField("RDS-DBINSTANCE-NAME", rdsName).
+			WithField("RDS-DB-NAME", dbName).
+			WithField("RDS-DB-CHARSET", req.CharacterSetName).Infoln("Database created")
+	}
+
+	for _, dbName := range created {
+		err = p.waitForRDSDatabaseRunning(dbInstanceId, dbName, 120)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (p *Aliyun) waitForRDSDatabaseRunning(dbInstanceId, dbName string, timeout int) (err error) {
+
+	if timeout <= 0 {
+		timeout = 60
+	}
+
+	waiter := p.newWaiter(fmt.Sprintf("database %s of db instance %s to Running", dbName, dbInstanceId), time.Duration(timeout)*time.Second, []string{"Running"}, "Deleting")
+	waiter.Fields["RDS-DBINSTANCE-ID"] = dbInstanceId
+	waiter.Fields["RDS-DB-NAME"] = dbName
+
+	// the database just created may not be listed yet, so NotFound is not terminal
+	err = waiter.Wait(context.Background(), func() (status string, e error) {
+		dbs, e := p.listRDSDatabases(dbInstanceId)
+		if e != nil {
+			return
+		}
+
+		db, exist := dbs[dbName]
+		if !exist {
+			status = WaitNotFound
+			return
+		}
+
+		status = db.DBStatus
+		return
+	})
+
+	return
+}
+
+func (p *Aliyun) CreateRDSDatabases() (err error) {
+
+	rdssConf := p.Config.GetConfig("aliyun.rds")
+
+	if rdssConf.IsEmpty() {
+		return
+	}
+
+	for _, rdsName := range rdssConf.Keys() {
+
+		rdsConf := rdssConf.GetConfig(rdsName)
+
+		databasesConf := rdsConf.GetConfig("databases")
+
+		if databasesConf.IsEmpty() {
+			continue
+		}
+
+		engine := rdsConf.GetString("engine", "MySQL")
+
+		var dbIns *rds.DBInstance
+		dbIns, err = p.FindRDSInstance(engine, rdsConf.GetString("vpc-name"), rdsConf.GetString("vswitch-name"), rdsName)
+		if err != nil {
+			return
+		}
+
+		if dbIns == nil {
+			err = fmt.Errorf("rds instance of %s not exist", rdsName)
+			return
+		}
+
+		err = p.createRDSInstanceDatabases(rdsName, engine, dbIns.DBInstanceId, databasesConf)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (p *Aliyun) DeleteRDSDatabases() (err error) {
+
+	rdssConf := p.Config.GetConfig("aliyun.rds")
+
+	if rdssConf.IsEmpty() {
+		return
+	}
+
+	for _, rdsName := range rdssConf.Keys() {
+
+		rdsConf := rdssConf.GetConfig(rdsName)
+
+		databasesConf := rdsConf.GetConfig("databases")
+
+		if databasesConf.IsEmpty() {
+			continue
+		}
+
+		engine := rdsConf.GetString("engine", "MySQL")
+
+		var dbIns *rds.DBInstance
+		dbIns, err = p.FindRDSInstance(engine, rdsConf.GetString("vpc-name"), rdsConf.GetString("vswitch-name"), rdsName)
+		if err != nil {
+			return
+		}
+
+		if dbIns == nil {
+			continue
+		}
+
+		var existDBs map[string]rds.Database
+		existDBs, err = p.listRDSDatabases(dbIns.DBInstanceId)
+		if err != nil {
+			return
+		}
+
+		for _, dbName := range databasesConf.Keys() {
+
+			if _, exist := existDBs[dbName]; !exist {
+				continue
+			}
+
+			req := rds.CreateDeleteDatabaseRequest()
+
+			req.DBInstanceId = dbIns.DBInstanceId
+			req.DBName = dbName
+
+			_, err = p.RDSClient().DeleteDatabase(req)
+			if err != nil {
+				return
+			}
+
+			logrus.WithField("CODE", p.Code).
+				WithField("RDS-DBINSTANCE-NAME", rdsName).
+				WithField("RDS-DB-NAME", dbName).Infoln("Database deleted")
+		}
+	}
+
+	return
+}
@@ -0,0 +1,278 @@
+package aliyun
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/rds"
+
+	"github.com/sirupsen/logrus"
+)
+
+type RDSEncryptionStatus struct {
+	InstanceId       string
+	InstanceName     string
+	SSLEnabled       bool
+	SSLConnection    string `json:",omitempty"`
+	SSLExpireTime    string `json:",omitempty"`
+	SSLRequireUpdate string `json:",omitempty"`
+	TDEStatus        string
+}
+
+func (p *Aliyun) describeRDSEncryption(dbInstanceId, name string) (status RDSEncryptionStatus, err error) {
+
+	status.InstanceId = dbInstanceId
+	status.InstanceName = name
+
+	sslReq := rds.CreateDescribeDBInstanceSSLRequest()
+	sslReq.DBInstanceId = dbInstanceId
+
+	sslResp, err := p.RDSClient().DescribeDBInstanceSSL(sslReq)
+	if err != nil {
+		return
+	}
+
+	status.SSLEnabled = len(sslResp.ConnectionString) > 0
+	status.SSLConnection = sslResp.ConnectionString
+	status.SSLExpireTime = sslResp.SSLExpireTime
+	status.SSLRequireUpdate = sslResp.RequireUpdate
+
+	tdeReq := rds.CreateDescribeDBInstanceTDERequest()
+	tdeReq.DBInstanceId = dbInstanceId
+
+	tdeResp, err := p.RDSClient().DescribeDBInstanceTDE(tdeReq)
+
+	if IsAliErrCode(err, "InvalidEngine.NotSupport") || IsAliErrCode(err, "IncorrectEngine") {
+		status.TDEStatus = "NotSupport"
+		err = nil
+		return
+	}
+
+	if err != nil {
+		return
+	}
+
+	status.TDEStatus = tdeResp.TDEStatus
+
+	return
+}
+
+func (p *Aliyun) DescribeRDSEncryption() (statuses []RDSEncryptionStatus, err error) {
+
+	insts, err := p.DescribeRDSInstancesAttr()
+	if err != nil {
+		return
+	}
+
+	for _, inst := range insts {
+
+		var status RDSEncryptionStatus
+		status, err = p.describeRDSEncryption(inst.DBInstanceId, inst.Name)
+		if err != nil {
+			return
+		}
+
+		logrus.WithField("CODE", p.Code).
+			WithField("RDS-DBINSTANCE-NAME", inst.Name).
+			WithField("SSL", status.SSLEnabled).
+			WithField("SSL-EXPIRE-TIME", status.SSLExpireTime).
+			WithField("TDE", status.TDEStatus).Infoln("Db instance encryption status")
+
+		statuses = append(statuses, status)
+	}
+
+	return
+}
+
+func (p *Aliyun) ApplyRDSEncryption() (err error) {
+
+	rdssConf := p.Config.GetConfig("aliyun.rds")
+
+	if rdssConf.IsEmpty() {
+		return
+	}
+
+	insts, err := p.DescribeRDSInstancesAttr()
+	if err != nil {
+		return
+	}
+
+	mapInsts := map[string]DBInstanceAttribute{}
+
+	for _, inst := range insts {
+		mapInsts[inst.Name] = inst
+	}
+
+	for _, rdsName := range rdssConf.Keys() {
+
+		rdsConf := rdssConf.GetConfig(rdsName)
+
+		sslConf := rdsConf.GetConfig("ssl")
+		tdeConf := rdsConf.GetConfig("tde")
+
+		if sslConf.IsEmpty() && tdeConf.IsEmpty() {
+			continue
+		}
+
+		inst, exist := mapInsts[rdsName]
+		if !exist {
+			err = fmt.Errorf("rds instance of %s not exist", rdsName)
+			return
+		}
+
+		var status RDSEncryptionStatus
+		status, err = p.describeRDSEncryption(inst.DBInstanceId, rdsName)
+		if err != nil {
+			return
+		}
+
+		if !sslConf.IsEmpty() {
+
+			enabled := sslConf.GetBoolean("enabled", true)
+			connStr := sslConf.GetString("connection-string", inst.ConnectionString)
+
+			if enabled != status.SSLEnabled || (enabled && connStr != status.SSLConnection) {
+
+				req := rds.CreateModifyDBInstanceSSLRequest()
+
+				req.DBInstanceId = inst.DBInstanceId
+				req.ConnectionString = connStr
+				req.SSLEnabled = requests.NewInteger(0)
+
+				if enabled {
+					req.SSLEnabled = requests.NewInteger(1)
+				}
+
+				_, err = p.RDSClient().ModifyDBInstanceSSL(req)
+				if err != nil {
+					err = fmt.Errorf("modify ssl of rds '%s' failure: %s", rdsName, err.Error())
+					return
+				}
+
+				logrus.WithField("CODE", p.Code).
+					WithField("RDS-DBINSTANCE-NAME", rdsName).
+					WithField("RDS-CONN-STR", connStr).
+					WithField("SSL", enabled).Infoln("Db instance ssl modified")
+
+				err = p.WaitForDBInstance(inst.DBInstanceId, "Running", 60*10)
+				if err != nil {
+					return
+				}
+			}
+
+			caPath := sslConf.GetString("ca-path")
+
+			if enabled && len(caPath) > 0 {
+				err = downloadRDSCA(sslConf.GetString("ca-url"), caPath)
+				if err != nil {
+					err = fmt.Errorf("download ca of rds '%s' failure: %s", rdsName, err.Error())
+					return
+				}
+
+				logrus.WithField("CODE", p.Code).
+					WithField("RDS-DBINSTANCE-NAME", rdsName).
+					WithField("CA-PATH", caPath).Infoln("Db instance ssl ca downloaded")
+			}
+		}
+
+		if !tdeConf.IsEmpty() && tdeConf.GetBoolean("enabled", true) && status.TDEStatus != "Enabled" {
+
+			if status.TDEStatus == "NotSupport" {
+				err = fmt.Errorf("tde is not supported by rds '%s' of %s %s", rdsName, inst.Engine, inst.EngineVersion)
+				return
+			}
+
+			req := rds.CreateModifyDBInstanceTDERequest()
+
+			req.DBInstanceId = inst.DBInstanceId
+			req.TDEStatus = "Enabled"
+
+			_, err = p.RDSClient().ModifyDBInstanceTDE(req)
+			if err != nil {
+				err = fmt.Errorf("enable tde of rds '%s' failure: %s", rdsName, err.Error())
+				return
+			}
+
+			logrus.WithField("CODE", p.Code).
+				WithField("RDS-DBINSTANCE-NAME", rdsName).Infoln("Db instance tde enabled")
+
+			err = p.WaitForDBInstance(inst.DBInstanceId, "Running", 60*10)
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// downloadRDSCA downloads the CA bundle of ApsaraDB, there is no API to fetch it,
+// so the url of bundle should be set in config of ssl.ca-url
+func downloadRDSCA(caUrl, caPath string) (err error) {
+
+	if len(caUrl) == 0 {
+		err = fmt.Errorf("ssl.ca-url is empty")
+		return
+	}
+
+	u, err := url.Parse(caUrl)
+	if err != nil {
+		err = fmt.Errorf("parse ssl.ca-url failure: %s", err.Error())
+		return
+	}
+
+	// the ca is the trust anchor of connections, it should not be fetched over plain http
+	if u.Scheme != "https" {
+		err = fmt.Errorf("ssl.ca-url should be https, but it is %s", caUrl)
+		return
+	}
+
+	client := &http.Client{Timeout: 60 * time.Second}
+
+	resp, err := client.Get(caUrl)
+	if err != nil {
+		return
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("unexpected status code %d of %s", resp.StatusCode, caUrl)
+		return
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		err = fmt.Errorf("the content of %s is not a pem encoded certificate", caUrl)
+		return
+	}
+
+	_, err = x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		err = fmt.Errorf("parse certificate of %s failure: %s", caUrl, err.Error())
+		return
+	}
+
+	err = os.MkdirAll(filepath.Dir(caPath), 0755)
+	if err != nil {
+		return
+	}
+
+	err = ioutil.WriteFile(caPath, data, 0644)
+
+	return
+}
@@ -0,0 +1,217 @@
+package aliyun
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RDSEngineProfile describes the defaults and account model of rds engine
+type RDSEngineProfile struct {
+	Engine string
+
+	// EngineVersions are the supported versions, the first one is the default
+	EngineVersions []string
+	// InstanceClassPrefixes are the prefixes of supported instance classes
+	InstanceClassPrefixes []string
+
+	DefaultInstanceClass string
+	MinStorage           int
+	Port                 string
+	Charset              string
+
+	DefaultAccountType string
+	// MaxSuperAccounts is the count of super accounts could be created, 0 means super account is not supported
+	MaxSuperAccounts int
+	Privileges       []string
+	DefaultPrivilege string
+
+	URLScheme     string
+	JDBCURLScheme string
+}
+
+var (
+	RDSEngineProfiles = map[string]*RDSEngineProfile{
+		"MySQL": {
+			Engine:                "MySQL",
+			EngineVersions:        []string{"5.6", "5.5", "5.7", "8.0"},
+			InstanceClassPrefixes: []string{"rds.mys2.", "rds.mysql.", "mysql."},
+			DefaultInstanceClass:  "rds.mys2.small",
+			MinStorage:            5,
+			Port:                  "3306",
+			Charset:               "utf8mb4",
+			DefaultAccountType:    "Normal",
+			MaxSuperAccounts:      1,
+			Privileges:            []string{"ReadWrite", "ReadOnly", "DDLOnly", "DMLOnly"},
+			DefaultPrivilege:      "ReadWrite",
+			URLScheme:             "mysql",
+			JDBCURLScheme:         "jdbc:mysql",
+		},
+		"MariaDB": {
+			Engine:                "MariaDB",
+			EngineVersions:        []string{"10.3"},
+			InstanceClassPrefixes: []string{"mariadb."},
+			DefaultInstanceClass:  "mariadb.x2.large.2c",
+			MinStorage:            20,
+			Port:                  "3306",
+			Charset:               "utf8mb4",
+			DefaultAccountType:    "Normal",
+			MaxSuperAccounts:      1,
+			Privileges:            []string{"ReadWrite", "ReadOnly", "DDLOnly", "DMLOnly"},
+			DefaultPrivilege:      "ReadWrite",
+			URLScheme:             "mysql",
+			JDBCURLScheme:         "jdbc:mariadb",
+		},
+		"SQLServer": {
+			Engine:                "SQLServer",
+			EngineVersions:        []string{"2012", "2008r2", "2012_web", "2012_std_ha", "2012_ent_ha", "2016_web", "2016_std_ha", "2016_ent_ha", "2017_ent"},
+			InstanceClassPrefixes: []string{"rds.mss1.", "rds.mssql.", "mssql."},
+			DefaultInstanceClass:  "rds.mssql.s2.large",
+			MinStorage:            20,
+			Port:                  "3433",
+			Charset:               "Chinese_PRC_CI_AS",
+			DefaultAccountType:    "Normal",
+			MaxSuperAccounts:      1,
+			Privileges:            []string{"ReadWrite", "ReadOnly", "DBOwner"},
+			DefaultPrivilege:      "DBOwner",
+			URLScheme:             "sqlserver",
+			JDBCURLScheme:         "jdbc:sqlserver",
+		},
+		"PostgreSQL": {
+			Engine:                "PostgreSQL",
+			EngineVersions:        []string{"10.0", "9.4", "11.0"},
+			InstanceClassPrefixes: []string{"rds.pg.", "pg."},
+			DefaultInstanceClass:  "rds.pg.s1.small",
+			MinStorage:            20,
+			Port:                  "3433",
+			Charset:               "UTF8",
+			DefaultAccountType:    "Normal",
+			MaxSuperAccounts:      1,
+			Privileges:            []string{"DBOwner"},
+			DefaultPrivilege:      "DBOwner",
+			URLScheme:             "postgres",
+			JDBCURLScheme:         "jdbc:postgresql",
+		},
+		"PPAS": {
+			Engine:                "PPAS",
+			EngineVersions:        []string{"10.0", "9.3"},
+			InstanceClassPrefixes: []string{"rds.ppas.", "ppas."},
+			DefaultInstanceClass:  "rds.ppas.t1.small",
+			MinStorage:            250,
+			Port:                  "3433",
+			Charset:               "UTF8",
+			DefaultAccountType:    "Normal",
+			MaxSuperAccounts:      1,
+			Privileges:            []string{"DBOwner"},
+			DefaultPrivilege:      "DBOwner",
+			URLScheme:             "postgres",
+			JDBCURLScheme:         "jdbc:edb",
+		},
+	}
+)
+
+func GetRDSEngineProfile(engine string) (profile *RDSEngineProfile, err error) {
+
+	profile, exist := RDSEngineProfiles[engine]
+	if !exist {
+		err = fmt.Errorf("rds engine of %s is not supported", engine)
+		return
+	}
+
+	return
+}
+
+// lookupRDSEngineProfile returns the profile of engine, an empty profile is returned for the unknown engine,
+// so the defaults and validations are left to the api
+func lookupRDSEngineProfile(engine string) (profile *RDSEngineProfile, known bool) {
+
+	profile, known = RDSEngineProfiles[engine]
+	if !known {
+		profile = &RDSEngineProfile{Engine: engine}
+	}
+
+	return
+}
+
+func (p *RDSEngineProfile) DefaultEngineVersion() string {
+	if len(p.EngineVersions) == 0 {
+		return ""
+	}
+
+	return p.EngineVersions[0]
+}
+
+// Validate checks the engine version, instance class and storage of instance against the known values,
+// it is advisory only because the api may support newer versions and classes
+func (p *RDSEngineProfile) Validate(engineVersion, instanceClass string, storage int) (err error) {
+
+	if !stringInSlice(engineVersion, p.EngineVersions) {
+		err = fmt.Errorf("the engine version %s of %s is not supported, supported versions: %s", engineVersion, p.Engine, strings.Join(p.EngineVersions, ","))
+		return
+	}
+
+	matched := false
+	for _, prefix := range p.InstanceClassPrefixes {
+		if strings.HasPrefix(instanceClass, prefix) {
+			matched = true
+			break
+		}
+	}
+
+	if !matched {
+		err = fmt.Errorf("the instance class %s is not belong to %s, it should be start with: %s", instanceClass, p.Engine, strings.Join(p.InstanceClassPrefixes, ","))
+		return
+	}
+
+	if storage < p.MinStorage {
+		err = fmt.Errorf("the instance storage of %s should be greater than or equal to %d", p.Engine, p.MinStorage)
+		return
+	}
+
+	return
+}
+
+// ValidatePrivilege checks the privilege could be granted to the account type
+func (p *RDSEngineProfile) ValidatePrivilege(accountType, privilege string) (err error) {
+
+	if accountType == "Super" {
+		err = fmt.Errorf("the super account of %s owns all databases, it could not be granted privilege", p.Engine)
+		return
+	}
+
+	if !stringInSlice(privilege, p.Privileges) {
+		err = fmt.Errorf("the privilege %s of %s is not supported, supported privileges: %s", privilege, p.Engine, strings.Join(p.Privileges, ","))
+		return
+	}
+
+	return
+}
+
+func (p *RDSEngineProfile) ConnectionURL(host, port, dbName string) string {
+
+	if len(port) == 0 {
+		port = p.Port
+	}
+
+	if len(dbName) == 0 {
+		return fmt.Sprintf("%s://%s:%s", p.URLScheme, host, port)
+	}
+
+	return fmt.Sprintf("%s://%s:%s/%s", p.URLScheme, host, port, dbName)
+}
+
+func (p *RDSEngineProfile) JDBCURL(host, port, dbName string) string {
+
+	if len(port) == 0 {
+		port = p.Port
+	}
+
+	if len(dbName) == 0 {
+		return fmt.Sprintf("%s://%s:%s", p.JDBCURLScheme, host, port)
+	}
+
+	if p.Engine == "SQLServer" {
+		return fmt.Sprintf("%s://%s:%s;databaseName=%s", p.JDBCURLScheme, host, port, dbName)
+	}
+
+	return fmt.Sprintf("%s://%s:%s/%s", p.JDBCURLScheme, host, port, dbName)
+}
@@ -0,0 +1,282 @@
+package aliyun
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/rds"
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+
+	"github.com/sirupsen/logrus"
+)
+
+const rdsLogTimeLayout = "2006-01-02T15:04Z"
+
+type RDSSlowLogRecord struct {
+	InstanceName string
+	rds.SQLSlowRecord
+}
+
+type RDSErrorLogRecord struct {
+	InstanceName string
+	rds.ErrorLog
+}
+
+type RDSSlowLogSummary struct {
+	InstanceName   string
+	DBName         string
+	SQLText        string
+	Count          int
+	TotalQueryTime int64
+	MaxQueryTime   int64
+}
+
+// ExportRDSLogs exports slow logs and error logs of aliyun.rds-logs.export time range,
+// and returns the top N slow statements order by total query time
+func (p *Aliyun) ExportRDSLogs() (summaries []RDSSlowLogSummary, err error) {
+
+	exportConf := p.Config.GetConfig("aliyun.rds-logs.export")
+
+	endTime := time.Now().UTC()
+	startTime := endTime.Add(-24 * time.Hour)
+
+	if since := exportConf.GetString("since"); len(since) > 0 {
+		var d time.Duration
+		d, err = time.ParseDuration(since)
+		if err != nil {
+			err = fmt.Errorf("parse rds logs export since failure: %s", err.Error())
+			return
+		}
+		startTime = endTime.Add(-d)
+	}
+
+	if str := exportConf.GetString("start-time"); len(str) > 0 {
+		startTime, err = time.Parse(rdsLogTimeLayout, str)
+		if err != nil {
+			return
+		}
+	}
+
+	if str := exportConf.GetString("end-time"); len(str) > 0 {
+		endTime, err = time.Parse(rdsLogTimeLayout, str)
+		if err != nil {
+			return
+		}
+	}
+
+	bucket := exportConf.GetString("oss-bucket")
+	dir := exportConf.GetString("path", "rds-logs")
+
+	if len(bucket) > 0 {
+		dir = exportConf.GetString("oss-prefix", dir)
+	}
+
+	insts, err := p.DescribeRDSInstancesAttr()
+	if err != nil {
+		return
+	}
+
+	var allSummaries []RDSSlowLogSummary
+
+	for _, inst := range insts {
+
+		var slowLogs []RDSSlowLogRecord
+		slowLogs, err = p.listRDSSlowLogs(inst.Name, inst.DBInstanceId, startTime, endTime)
+		if err != nil {
+			err = fmt.Errorf("describe slow logs of rds '%s' failure: %s", inst.Name, err.Error())
+			return
+		}
+
+		var errorLogs []RDSErrorLogRecord
+		errorLogs, err = p.listRDSErrorLogs(inst.Name, inst.DBInstanceId, startTime, endTime)
+		if err != nil {
+			err = fmt.Errorf("describe error logs of rds '%s' failure: %s", inst.Name, err.Error())
+			return
+		}
+
+		var slowItems, errorItems []interface{}
+
+		for _, item := range slowLogs {
+			slowItems = append(slowItems, item)
+		}
+
+		for _, item := range errorLogs {
+			errorItems = append(errorItems, item)
+		}
+
+		err = p.writeRDSLogs(dir, bucket, fmt.Sprintf("%s-slow.jsonl", inst.Name), slowItems)
+		if err != nil {
+			return
+		}
+
+		err = p.writeRDSLogs(dir, bucket, fmt.Sprintf("%s-error.jsonl", inst.Name), errorItems)
+		if err != nil {
+			return
+		}
+
+		logrus.WithField("CODE", p.Code).
+			WithField("RDS-DBINSTANCE-NAME", inst.Name).
+			WithField("SLOW-LOGS", len(slowLogs)).
+			WithField("ERROR-LOGS", len(errorLogs)).
+			WithField("START-TIME", startTime.Format(rdsLogTimeLayout)).
+			WithField("END-TIME", endTime.Format(rdsLogTimeLayout)).Infoln("Db instance logs exported")
+
+		allSummaries = append(allSummaries, summarizeRDSSlowLogs(slowLogs)...)
+	}
+
+	sort.Slice(allSummaries, func(i, j int) bool {
+		return allSummaries[i].TotalQueryTime > allSummaries[j].TotalQueryTime
+	})
+
+	top := int(exportConf.GetInt32("top", 10))
+
+	if top > 0 && len(allSummaries) > top {
+		allSummaries = allSummaries[:top]
+	}
+
+	summaries = allSummaries
+
+	return
+}
+
+func (p *Aliyun) listRDSSlowLogs(rdsName, dbInstanceId string, startTime, endTime time.Time) (records []RDSSlowLogRecord, err error) {
+
+	for pageNumber := 1; ; pageNumber++ {
+
+		req := rds.CreateDescribeSlowLogRecordsRequest()
+
+		req.DBInstanceId = dbInstanceId
+		req.StartTime = startTime.Format(rdsLogTimeLayout)
+		req.EndTime = endTime.Format(rdsLogTimeLayout)
+		req.PageSize = requests.NewInteger(100)
+		req.PageNumber = requests.NewInteger(pageNumber)
+
+		var resp *rds.DescribeSlowLogRecordsResponse
+		resp, err = p.RDSClient().DescribeSlowLogRecords(req)
+		if err != nil {
+			return
+		}
+
+		for _, record := range resp.Items.SQLSlowRecord {
+			records = append(records, RDSSlowLogRecord{InstanceName: rdsName, SQLSlowRecord: record})
+		}
+
+		if len(resp.Items.SQLSlowRecord) == 0 || len(records) >= resp.TotalRecordCount {
+			return
+		}
+	}
+}
+
+func (p *Aliyun) listRDSErrorLogs(rdsName, dbInstanceId string, startTime, endTime time.Time) (records []RDSErrorLogRecord, err error) {
+
+	for pageNumber := 1; ; pageNumber++ {
+
+		req := rds.CreateDescribeErrorLogsRequest()
+
+		req.DBInstanceId = dbInstanceId
+		req.StartTime = startTime.Format(rdsLogTimeLayout)
+		req.EndTime = endTime.Format(rdsLogTimeLayout)
+		req.PageSize = requests.NewInteger(100)
+		req.PageNumber = requests.NewInteger(pageNumber)
+
+		var resp *rds.DescribeErrorLogsResponse
+		resp, err = p.RDSClient().DescribeErrorLogs(req)
+		if err != nil {
+			return
+		}
+
+		for _, record := range resp.Items.ErrorLog {
+			records = append(records, RDSErrorLogRecord{InstanceName: rdsName, ErrorLog: record})
+		}
+
+		if len(resp.Items.ErrorLog) == 0 || len(records) >= resp.TotalRecordCount {
+			return
+		}
+	}
+}
+
+// writeRDSLogs writes items as json lines into oss bucket with prefix of dir if bucket is not empty, or else into local dir
+func (p *Aliyun) writeRDSLogs(dir, bucket, filename string, items []interface{}) (err error) {
+
+	buf := bytes.NewBuffer(nil)
+	encoder := json.NewEncoder(buf)
+
+	for _, item := range items {
+		err = encoder.Encode(item)
+		if err != nil {
+			return
+		}
+	}
+
+	if len(bucket) > 0 {
+
+		var ossBucket *oss.Bucket
+		ossBucket, err = p.OSSClient().Bucket(bucket)
+		if err != nil {
+			return
+		}
+
+		objectKey := path.Join(dir, filename)
+
+		err = ossBucket.PutObject(objectKey, buf)
+		if err != nil {
+			err = fmt.Errorf("put rds logs to oss://%s/%s failure: %s", bucket, objectKey, err.Error())
+			return
+		}
+
+		return
+	}
+
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return
+	}
+
+	err = ioutil.WriteFile(filepath.Join(dir, filename), buf.Bytes(), 0644)
+
+	return
+}
+
+func summarizeRDSSlowLogs(records []RDSSlowLogRecord) (summaries []RDSSlowLogSummary) {
+
+	mapSummaries := map[string]*RDSSlowLogSummary{}
+
+	var keys []string
+
+	for _, record := range records {
+
+		key := record.DBName + "\n" + record.SQLText
+
+		summary, exist := mapSummaries[key]
+		if !exist {
+			summary = &RDSSlowLogSummary{
+				InstanceName: record.InstanceName,
+				DBName:       record.DBName,
+				SQLText:      record.SQLText,
+			}
+			mapSummaries[key] = summary
+			keys = append(keys, key)
+		}
+
+		summary.Count++
+		summary.TotalQueryTime += int64(record.QueryTimes)
+
+		if int64(record.QueryTimes) > summary.MaxQueryTime {
+			summary.MaxQueryTime = int64(record.QueryTimes)
+		}
+	}
+
+	for _, key := range keys {
+		summaries = append(summaries, *mapSummaries[key])
+	}
+
+	return
+}
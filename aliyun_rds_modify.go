@@ -0,0 +1,137 @@
+package aliyun
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/rds"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ModifyRDSInstances scales the existing instances to the class, storage and engine version in config
+func (p *Aliyun) ModifyRDSInstances() (err error) {
+
+	rdssConf := p.Config.GetConfig("aliyun.rds")
+
+	if rdssConf.IsEmpty() {
+		return
+	}
+
+	insts, err := p.DescribeRDSInstancesAttr()
+	if err != nil {
+		return
+	}
+
+	mapInsts := map[string]DBInstanceAttribute{}
+
+	for _, inst := range insts {
+		mapInsts[inst.Name] = inst
+	}
+
+	var waitInstIds []string
+
+	for _, rdsName := range rdssConf.Keys() {
+
+		rdsConf := rdssConf.GetConfig(rdsName)
+
+		inst, exist := mapInsts[rdsName]
+		if !exist {
+			logrus.WithField("CODE", p.Code).WithField("DBINSTANCE-NAME", rdsName).Infoln("RDS Instance not exist")
+			continue
+		}
+
+		effectiveTime := "Immediate"
+		if rdsConf.GetBoolean("modify.maintain-window", false) {
+			effectiveTime = "MaintainTime"
+		}
+
+		modified := false
+
+		engineVersion := rdsConf.GetString("engine-version", inst.EngineVersion)
+
+		if engineVersion != inst.EngineVersion {
+
+			req := rds.CreateUpgradeDBInstanceEngineVersionRequest()
+
+			req.DBInstanceId = inst.DBInstanceId
+			req.EngineVersion = engineVersion
+			req.EffectiveTime = effectiveTime
+
+			_, err = p.RDSClient().UpgradeDBInstanceEngineVersion(req)
+			if err != nil {
+				err = fmt.Errorf("upgrade engine version of rds '%s' from %s to %s failure: %s", rdsName, inst.EngineVersion, engineVersion, err.Error())
+				return
+			}
+
+			logrus.WithField("CODE", p.Code).
+				WithField("RDS-DBINSTANCE-NAME", rdsName).
+				WithField("RDS-ENGINE-VERSION", inst.EngineVersion+" => "+engineVersion).
+				WithField("EFFECTIVE-TIME", effectiveTime).Infoln("Db instance engine version upgrading")
+
+			modified = true
+
+			// the spec could not be modified until the upgrading finished
+			if effectiveTime == "Immediate" {
+				err = p.waitForDBInstanceTransition(context.Background(), inst.DBInstanceId, "modifying", rdsTransitionLeaveTimeout, 60*60)
+				if err != nil {
+					return
+				}
+			}
+		}
+
+		instClass := rdsConf.GetString("instance-class", inst.DBInstanceClass)
+		instStorage := int(rdsConf.GetInt32("instance-storage", int32(inst.DBInstanceStorage)))
+
+		if instClass != inst.DBInstanceClass || instStorage != int(inst.DBInstanceStorage) {
+
+			if instStorage < int(inst.DBInstanceStorage) {
+				err = fmt.Errorf("the instance-storage of rds '%s' could not be reduced from %d to %d", rdsName, inst.DBInstanceStorage, instStorage)
+				return
+			}
+
+			req := rds.CreateModifyDBInstanceSpecRequest()
+
+			req.DBInstanceId = inst.DBInstanceId
+			req.DBInstanceClass = instClass
+			req.DBInstanceStorage = requests.NewInteger(instStorage)
+			req.PayType = inst.PayType
+			req.EffectiveTime = effectiveTime
+
+			_, err = p.RDSClient().ModifyDBInstanceSpec(req)
+			if err != nil {
+				err = fmt.Errorf("modify spec of rds '%s' failure: %s", rdsName, err.Error())
+				return
+			}
+
+			logrus.WithField("CODE", p.Code).
+				WithField("RDS-DBINSTANCE-NAME", rdsName).
+				WithField("RDS-INSTANCE-CLASS", inst.DBInstanceClass+" => "+instClass).
+				WithField("RDS-INSTANCE-STORAGE", fmt.Sprintf("%d => %d", inst.DBInstanceStorage, instStorage)).
+				WithField("EFFECTIVE-TIME", effectiveTime).Infoln("Db instance spec modifying")
+
+			modified = true
+
+			if effectiveTime == "Immediate" {
+				waitInstIds = append(waitInstIds, inst.DBInstanceId)
+			}
+		}
+
+		if !modified {
+			logrus.WithField("CODE", p.Code).WithField("RDS-DBINSTANCE-NAME", rdsName).Infoln("Db instance spec not changed")
+			continue
+		}
+	}
+
+	for _, instId := range waitInstIds {
+		err = p.waitForDBInstanceTransition(context.Background(), instId, "modifying", rdsTransitionLeaveTimeout, 60*60)
+		if err != nil {
+			return
+		}
+
+		logrus.WithField("CODE", p.Code).WithField("RDS-DBINSTANCE-ID", instId).Infoln("Db instance modified")
+	}
+
+	return
+}
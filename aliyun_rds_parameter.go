@@ -0,0 +1,220 @@
+package aliyun
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/rds"
+
+	"github.com/sirupsen/logrus"
+)
+
+type RDSParameterChange struct {
+	InstanceName   string
+	ParameterName  string
+	CurrentValue   string
+	DesiredValue   string
+	RequireRestart bool
+	Restarted      bool
+}
+
+func (p *Aliyun) rdsParameterTemplates(engine, engineVersion string) (templates map[string]rds.TemplateRecord, err error) {
+
+	req := rds.CreateDescribeParameterTemplatesRequest()
+
+	req.Engine = engine
+	req.EngineVersion = engineVersion
+
+	resp, err := p.RDSClient().DescribeParameterTemplates(req)
+	if err != nil {
+		return
+	}
+
+	templates = make(map[string]rds.TemplateRecord)
+
+	for _, record := range resp.Parameters.TemplateRecord {
+		templates[record.ParameterName] = record
+	}
+
+	return
+}
+
+func (p *Aliyun) SyncRDSParameters() (changes []RDSParameterChange, err error) {
+
+	rdssConf := p.Config.GetConfig("aliyun.rds")
+
+	if rdssConf.IsEmpty() {
+		return
+	}
+
+	for _, rdsName := range rdssConf.Keys() {
+
+		rdsConf := rdssConf.GetConfig(rdsName)
+
+		paramsConf := rdsConf.GetConfig("parameters")
+
+		if paramsConf.IsEmpty() {
+			continue
+		}
+
+		engine := rdsConf.GetString("engine", "MySQL")
+
+		var dbIns *rds.DBInstance
+		dbIns, err = p.FindRDSInstance(engine, rdsConf.GetString("vpc-name"), rdsConf.GetString("vswitch-name"), rdsName)
+		if err != nil {
+			return
+		}
+
+		if dbIns == nil {
+			err = fmt.Errorf("rds instance of %s not exist", rdsName)
+			return
+		}
+
+		req := rds.CreateDescribeParametersRequest()
+		req.DBInstanceId = dbIns.DBInstanceId
+
+		var resp *rds.DescribeParametersResponse
+		resp, err = p.RDSClient().DescribeParameters(req)
+		if err != nil {
+			return
+		}
+
+		running := map[string]string{}
+
+		for _, param := range resp.RunningParameters.DBInstanceParameter {
+			running[param.ParameterName] = param.ParameterValue
+		}
+
+		var templates map[string]rds.TemplateRecord
+		templates, err = p.rdsParameterTemplates(dbIns.Engine, dbIns.EngineVersion)
+		if err != nil {
+			return
+		}
+
+		var instChanges []RDSParameterChange
+
+		modifyParams := map[string]string{}
+		requireRestart := false
+
+		names := paramsConf.Keys()
+		sort.Strings(names)
+
+		for _, name := range names {
+
+			desired := paramsConf.GetString(name)
+
+			current, exist := running[name]
+			if !exist {
+				err = fmt.Errorf("parameter %s of rds %s is not supported by %s %s", name, rdsName, dbIns.Engine, dbIns.EngineVersion)
+				return
+			}
+
+			if current == desired {
+				continue
+			}
+
+			template := templates[name]
+
+			restart, _ := strconv.ParseBool(template.ForceRestart)
+
+			requireRestart = requireRestart || restart
+
+			modifyParams[name] = desired
+
+			instChanges = append(instChanges, RDSParameterChange{
+				InstanceName:   rdsName,
+				ParameterName:  name,
+				CurrentValue:   current,
+				DesiredValue:   desired,
+				RequireRestart: restart,
+			})
+		}
+
+		if len(modifyParams) == 0 {
+			logrus.WithField("CODE", p.Code).WithField("RDS-DBINSTANCE-NAME", rdsName).Infoln("Db instance parameters not changed")
+			continue
+		}
+
+		restart := requireRestart && rdsConf.GetBoolean("parameters-restart", false)
+
+		var paramsData []byte
+		paramsData, err = json.Marshal(modifyParams)
+		if err != nil {
+			return
+		}
+
+		modifyReq := rds.CreateModifyParameterRequest()
+
+		modifyReq.DBInstanceId = dbIns.DBInstanceId
+		modifyReq.Parameters = string(paramsData)
+		modifyReq.Forcerestart = requests.NewBoolean(false)
+
+		_, err = p.RDSClient().ModifyParameter(modifyReq)
+		if err != nil {
+			err = fmt.Errorf("modify parameters of rds '%s' failure: %s", rdsName, err.Error())
+			return
+		}
+
+		for i := range instChanges {
+
+			instChanges[i].Restarted = restart && instChanges[i].RequireRestart
+
+			entry := logrus.WithField("CODE", p.Code).
+				WithField("RDS-DBINSTANCE-NAME", rdsName).
+				WithField("PARAMETER", instChanges[i].ParameterName).
+				WithField("VALUE", instChanges[i].CurrentValue+" => "+instChanges[i].DesiredValue)
+
+			if instChanges[i].RequireRestart && !restart {
+				entry.Warnln("Db instance parameter modified, it will take effect after restart")
+				continue
+			}
+
+			entry.Infoln("Db instance parameter modified")
+		}
+
+		if restart {
+			err = p.restartRDSInstance(rdsName, dbIns.DBInstanceId)
+			if err != nil {
+				return
+			}
+		}
+
+		changes = append(changes, instChanges...)
+	}
+
+	return
+}
+
+func (p *Aliyun) restartRDSInstance(rdsName, dbInstanceId string) (err error) {
+
+	// the instance status is ModifyingParameters while parameters applying, it still reports Running
+	// right after the parameters modified, so wait for it to leave Running and come back
+	err = p.waitForDBInstanceTransition(context.Background(), dbInstanceId, "ModifyingParameters", rdsTransitionLeaveTimeout, 60*10)
+	if err != nil {
+		return
+	}
+
+	req := rds.CreateRestartDBInstanceRequest()
+	req.DBInstanceId = dbInstanceId
+
+	_, err = p.RDSClient().RestartDBInstance(req)
+	if err != nil {
+		err = fmt.Errorf("restart rds '%s' failure: %s", rdsName, err.Error())
+		return
+	}
+
+	logrus.WithField("CODE", p.Code).WithField("RDS-DBINSTANCE-NAME", rdsName).Infoln("Db instance restarting")
+
+	err = p.waitForDBInstanceTransition(context.Background(), dbInstanceId, "restarting", rdsTransitionLeaveTimeout, 60*30)
+	if err != nil {
+		return
+	}
+
+	logrus.WithField("CODE", p.Code).WithField("RDS-DBINSTANCE-NAME", rdsName).Infoln("Db instance restarted")
+
+	return
+}
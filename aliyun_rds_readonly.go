@@ -0,0 +1,162 @@
+package aliyun
+
+import (
+	"fmt"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/rds"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/vpc"
+
+	"github.com/sirupsen/logrus"
+)
+
+// CreateRDSReadOnlyInstances creates the read-only instances of aliyun.rds.<name>.read-only,
+// the replicas are tagged with the same convention as primary, and with tag of primary
+func (p *Aliyun) CreateRDSReadOnlyInstances() (err error) {
+
+	rdssConf := p.Config.GetConfig("aliyun.rds")
+
+	if rdssConf.IsEmpty() {
+		return
+	}
+
+	for _, rdsName := range rdssConf.Keys() {
+
+		rdsConf := rdssConf.GetConfig(rdsName)
+
+		replicasConf := rdsConf.GetConfig("read-only")
+
+		if replicasConf.IsEmpty() {
+			continue
+		}
+
+		vpcName := rdsConf.GetString("vpc-name")
+		engine := rdsConf.GetString("engine", "MySQL")
+
+		var primary *rds.DBInstance
+		primary, err = p.FindRDSInstance(engine, vpcName, rdsConf.GetString("vswitch-name"), rdsName)
+		if err != nil {
+			return
+		}
+
+		if primary == nil {
+			err = fmt.Errorf("primary rds instance of %s not exist", rdsName)
+			return
+		}
+
+		for _, replicaName := range replicasConf.Keys() {
+
+			replicaConf := replicasConf.GetConfig(replicaName)
+
+			replicaVPCName := replicaConf.GetString("vpc-name", vpcName)
+			replicaVSwitchName := replicaConf.GetString("vswitch-name", rdsConf.GetString("vswitch-name"))
+
+			var replica *rds.DBInstance
+			replica, err = p.FindRDSInstance(engine, replicaVPCName, replicaVSwitchName, replicaName)
+			if err != nil {
+				return
+			}
+
+			if replica != nil {
+				logrus.WithField("CODE", p.Code).
+					WithField("RDS", replica.DBInstanceId).
+					WithField("DBINSTANCE-NAME", replicaName).Infoln("RDS read-only instance already created")
+				continue
+			}
+
+			var vSwitch *vpc.VSwitch
+			vSwitch, err = p.FindVSwitch(replicaVPCName, replicaVSwitchName)
+			if err != nil {
+				return
+			}
+
+			if vSwitch == nil {
+				err = fmt.Errorf("rds read-only instance of %s vswitch is not found", replicaName)
+				return
+			}
+
+			req := rds.CreateCreateReadOnlyDBInstanceRequest()
+
+			req.RegionId = p.Region
+			req.DBInstanceId = primary.DBInstanceId
+			req.ZoneId = replicaConf.GetString("zone-id", vSwitch.ZoneId)
+			req.EngineVersion = rdsConf.GetString("engine-version", primary.EngineVersion)
+			req.DBInstanceClass = replicaConf.GetString("instance-class", primary.DBInstanceClass)
+			req.DBInstanceStorage = requests.NewInteger(int(replicaConf.GetInt32("instance-storage", rdsConf.GetInt32("instance-storage", 5))))
+			req.DBInstanceDescription = replicaName
+			req.PayType = replicaConf.GetString("pay-type", "Postpaid")
+			req.InstanceNetworkType = "VPC"
+			req.VPCId = vSwitch.VpcId
+			req.VSwitchId = vSwitch.VSwitchId
+			req.PrivateIpAddress = replicaConf.GetString("private-ip-address")
+
+			var resp *rds.CreateReadOnlyDBInstanceResponse
+			resp, err = p.RDSClient().CreateReadOnlyDBInstance(req)
+			if err != nil {
+				err = fmt.Errorf("create read-only instance '%s' of rds '%s' failure: %s", replicaName, rdsName, err.Error())
+				return
+			}
+
+			err = p.addRDSInstanceTags(resp.DBInstanceId, replicaName, Tag{Key: "primary", Value: rdsName})
+			if err != nil {
+				return
+			}
+
+			logrus.WithField("CODE", p.Code).
+				WithField("RDS-DBINSTANCE-ID", resp.DBInstanceId).
+				WithField("RDS-PRIMARY-ID", primary.DBInstanceId).
+				WithField("RDS-PRIMARY-NAME", rdsName).
+				WithField("RDS-CONN-STR", resp.ConnectionString).
+				WithField("RDS-VSWITCH-ID", req.VSwitchId).
+				Infoln("Db read-only instance created")
+		}
+	}
+
+	return
+}
+
+func (p *Aliyun) DeleteRDSReadOnlyInstances() (err error) {
+
+	rdssConf := p.Config.GetConfig("aliyun.rds")
+
+	if rdssConf.IsEmpty() {
+		return
+	}
+
+	for _, rdsName := range rdssConf.Keys() {
+
+		rdsConf := rdssConf.GetConfig(rdsName)
+
+		replicasConf := rdsConf.GetConfig("read-only")
+
+		if replicasConf.IsEmpty() {
+			continue
+		}
+
+		for _, replicaName := range replicasConf.Keys() {
+
+			var resp *rds.DescribeDBInstancesResponse
+			resp, err = p.listRDSInstance(map[string]string{"name": replicaName, "primary": rdsName})
+			if err != nil {
+				return
+			}
+
+			for _, replica := range resp.Items.DBInstance {
+
+				req := rds.CreateDeleteDBInstanceRequest()
+				req.DBInstanceId = replica.DBInstanceId
+
+				_, err = p.RDSClient().DeleteDBInstance(req)
+				if err != nil {
+					return
+				}
+
+				logrus.WithField("CODE", p.Code).
+					WithField("RDS-DBINSTANCE-ID", replica.DBInstanceId).
+					WithField("RDS-PRIMARY-NAME", rdsName).Infoln("Db read-only instance deleted")
+			}
+		}
+	}
+
+	return
+}
@@ -0,0 +1,277 @@
+package aliyun
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/ecs"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/rds"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/vpc"
+	"github.com/gogap/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+// whitelistGroupIPs collects ips, vswitch cidr blocks and ecs private ips of whitelist group,
+// the resource is used in error messages, e.g. "rds mydb"
+func (p *Aliyun) whitelistGroupIPs(resource, vpcName string, groupConf config.Configuration) (ips []string, err error) {
+
+	mapIPs := map[string]bool{}
+
+	for _, ip := range groupConf.GetStringList("ips") {
+		mapIPs[ip] = true
+	}
+
+	for _, vSwitchName := range groupConf.GetStringList("vswitches") {
+
+		switchVPCName := vpcName
+
+		// the vswitch in other vpc could be referenced by <vpc-name>/<vswitch-name>
+		if idx := strings.Index(vSwitchName, "/"); idx > 0 {
+			switchVPCName = vSwitchName[:idx]
+			vSwitchName = vSwitchName[idx+1:]
+		}
+
+		var vSwitch *vpc.VSwitch
+		vSwitch, err = p.FindVSwitch(switchVPCName, vSwitchName)
+		if err != nil {
+			return
+		}
+
+		if vSwitch == nil {
+			err = fmt.Errorf("whitelist of %s's vswitch %s in vpc %s is not found", resource, vSwitchName, switchVPCName)
+			return
+		}
+
+		mapIPs[vSwitch.CidrBlock] = true
+	}
+
+	ecsConf := groupConf.GetConfig("ecs")
+
+	for _, ecsName := range ecsConf.Keys() {
+
+		searchConf := ecsConf.GetConfig(ecsName)
+
+		var tags []Tag
+
+		tagConf := searchConf.GetConfig("tag")
+
+		for _, k := range tagConf.Keys() {
+			tags = append(tags, Tag{Key: k, Value: tagConf.GetString(k)})
+		}
+
+		var inst *ecs.Instance
+		inst, err = p.FindECSInstance(
+			&SearchECSInstanceArgs{
+				InstanceId:   searchConf.GetString("id"),
+				InstanceName: searchConf.GetString("name", ecsName),
+				NetworkType:  searchConf.GetString("network-type"),
+				ZoneId:       searchConf.GetString("zone-id"),
+				VPCName:      searchConf.GetString("vpc-name"),
+				VSwitchName:  searchConf.GetString("vswitch-name"),
+				Tags:         tags,
+			},
+		)
+
+		if err != nil {
+			return
+		}
+
+		if inst == nil {
+			err = fmt.Errorf("whitelist of %s's ecs instance %s is not found, tags: %#v", resource, ecsName, tags)
+			return
+		}
+
+		instIPs := inst.VpcAttributes.PrivateIpAddress.IpAddress
+
+		if len(instIPs) == 0 {
+			instIPs = inst.InnerIpAddress.IpAddress
+		}
+
+		for _, ip := range instIPs {
+			mapIPs[ip] = true
+		}
+	}
+
+	for ip := range mapIPs {
+		ips = append(ips, ip)
+	}
+
+	sort.Strings(ips)
+
+	return
+}
+
+// syncWhitelistGroups covers the security ips of each whitelist group by modify, and waits for the instance
+// by wait before the next group, the instance could not be modified while the last modification is in progress,
+// the group is skipped while unchanged (optional) reports its current ips are equal to the sorted security ips
+func (p *Aliyun) syncWhitelistGroups(resource, vpcName string, whitelistConf config.Configuration,
+	unchanged func(groupName, securityIps string) bool, modify func(groupName, securityIps string) error, wait func() error) (err error) {
+
+	for _, groupName := range whitelistConf.Keys() {
+
+		var ips []string
+		ips, err = p.whitelistGroupIPs(resource, vpcName, whitelistConf.GetConfig(groupName))
+		if err != nil {
+			return
+		}
+
+		if len(ips) == 0 {
+			err = fmt.Errorf("whitelist group %s of %s is empty", groupName, resource)
+			return
+		}
+
+		securityIps := strings.Join(ips, ",")
+
+		if unchanged != nil && unchanged(groupName, securityIps) {
+			logrus.WithField("CODE", p.Code).
+				WithField("RESOURCE", resource).
+				WithField("WHITELIST-GROUP", groupName).Debugln("Whitelist group not changed")
+			continue
+		}
+
+		err = modify(groupName, securityIps)
+		if err != nil {
+			err = fmt.Errorf("modify security ips of %s, group '%s' failure: %s", resource, groupName, err.Error())
+			return
+		}
+
+		logrus.WithField("CODE", p.Code).
+			WithField("RESOURCE", resource).
+			WithField("WHITELIST-GROUP", groupName).
+			WithField("WHITELIST-IPS", securityIps).Infoln("Whitelist group synced")
+
+		err = wait()
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (p *Aliyun) SyncRDSWhitelist() (err error) {
+
+	rdssConf := p.Config.GetConfig("aliyun.rds")
+
+	if rdssConf.IsEmpty() {
+		return
+	}
+
+	for _, rdsName := range rdssConf.Keys() {
+
+		rdsConf := rdssConf.GetConfig(rdsName)
+
+		whitelistConf := rdsConf.GetConfig("whitelist")
+
+		if whitelistConf.IsEmpty() {
+			continue
+		}
+
+		vpcName := rdsConf.GetString("vpc-name")
+		engine := rdsConf.GetString("engine", "MySQL")
+
+		var dbIns *rds.DBInstance
+		dbIns, err = p.FindRDSInstance(engine, vpcName, rdsConf.GetString("vswitch-name"), rdsName)
+		if err != nil {
+			return
+		}
+
+		if dbIns == nil {
+			err = fmt.Errorf("rds instance of %s not exist", rdsName)
+			return
+		}
+
+		req := rds.CreateDescribeDBInstanceIPArrayListRequest()
+		req.DBInstanceId = dbIns.DBInstanceId
+
+		var resp *rds.DescribeDBInstanceIPArrayListResponse
+		resp, err = p.RDSClient().DescribeDBInstanceIPArrayList(req)
+		if err != nil {
+			return
+		}
+
+		existGroups := map[string]rds.DBInstanceIPArray{}
+
+		for _, group := range resp.Items.DBInstanceIPArray {
+			existGroups[group.DBInstanceIPArrayName] = group
+		}
+
+		err = p.syncWhitelistGroups("rds "+rdsName, vpcName, whitelistConf,
+			func(groupName, securityIps string) bool {
+
+				group, exist := existGroups[groupName]
+				if !exist {
+					return false
+				}
+
+				current := strings.Split(group.SecurityIPList, ",")
+				sort.Strings(current)
+
+				return strings.Join(current, ",") == securityIps
+			},
+			func(groupName, securityIps string) error {
+				return p.modifyRDSSecurityIps(dbIns.DBInstanceId, groupName, securityIps, "Cover")
+			},
+			func() error {
+				return p.waitForRDSSecurityIpsModified(dbIns.DBInstanceId)
+			},
+		)
+		if err != nil {
+			return
+		}
+
+		if !rdsConf.GetBoolean("whitelist-prune", false) {
+			continue
+		}
+
+		for groupName, group := range existGroups {
+
+			if groupName == "default" ||
+				group.DBInstanceIPArrayAttribute == "hidden" ||
+				whitelistConf.HasPath(groupName) {
+				continue
+			}
+
+			err = p.modifyRDSSecurityIps(dbIns.DBInstanceId, groupName, group.SecurityIPList, "Delete")
+			if err != nil {
+				err = fmt.Errorf("delete whitelist group '%s' of rds %s failure: %s", groupName, rdsName, err.Error())
+				return
+			}
+
+			err = p.waitForRDSSecurityIpsModified(dbIns.DBInstanceId)
+			if err != nil {
+				return
+			}
+
+			logrus.WithField("CODE", p.Code).
+				WithField("RDS-DBINSTANCE-NAME", rdsName).
+				WithField("RDS-WHITELIST-GROUP", groupName).Infoln("Whitelist group deleted")
+		}
+	}
+
+	return
+}
+
+func (p *Aliyun) modifyRDSSecurityIps(dbInstanceId, groupName, securityIps, mode string) (err error) {
+
+	req := rds.CreateModifySecurityIpsRequest()
+
+	req.DBInstanceId = dbInstanceId
+	req.DBInstanceIPArrayName = groupName
+	req.SecurityIps = securityIps
+	req.ModifyMode = mode
+
+	_, err = p.RDSClient().ModifySecurityIps(req)
+
+	return
+}
+
+// waitForRDSSecurityIpsModified waits for the instance back to running after security ips modified,
+// the instance could not be modified again until then, the modification is quick, so the leave window is short
+func (p *Aliyun) waitForRDSSecurityIpsModified(dbInstanceId string) error {
+	return p.waitForDBInstanceTransition(context.Background(), dbInstanceId, "modifying security ips", 30*time.Second, 300)
+}
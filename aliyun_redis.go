@@ -0,0 +1,353 @@
+package aliyun
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/r-kvstore"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/vpc"
+
+	"github.com/sirupsen/logrus"
+)
+
+type RedisInstance struct {
+	Name string
+	r_kvstore.KVStoreInstance
+}
+
+// redisTerminalStatus is the status of redis instance which would not reach to others while waiting
+var redisTerminalStatus = []string{"Released", "Inactive", "Error"}
+
+func (p *Aliyun) listRedisInstances(tags map[string]string) (insts []RedisInstance, err error) {
+
+	var reqTags []r_kvstore.DescribeInstancesTag
+
+	for k, v := range p.codeTags(tags) {
+		reqTags = append(reqTags, r_kvstore.DescribeInstancesTag{Key: k, Value: v})
+	}
+
+	for pageNumber := 1; ; pageNumber++ {
+
+		req := r_kvstore.CreateDescribeInstancesRequest()
+
+		req.RegionId = p.Region
+		req.Tag = &reqTags
+		req.PageSize = requests.NewInteger(50)
+		req.PageNumber = requests.NewInteger(pageNumber)
+
+		var resp *r_kvstore.DescribeInstancesResponse
+		resp, err = p.RedisClient().DescribeInstances(req)
+		if err != nil {
+			return
+		}
+
+		for _, inst := range resp.Instances.KVStoreInstance {
+			insts = append(insts, RedisInstance{Name: inst.InstanceName, KVStoreInstance: inst})
+		}
+
+		if len(resp.Instances.KVStoreInstance) == 0 || len(insts) >= resp.TotalCount {
+			return
+		}
+	}
+}
+
+func (p *Aliyun) FindRedisInstance(redisName string) (inst *RedisInstance, err error) {
+
+	insts, err := p.listRedisInstances(map[string]string{"name": redisName})
+	if err != nil {
+		return
+	}
+
+	if len(insts) == 0 {
+		return
+	}
+
+	inst = &insts[0]
+
+	return
+}
+
+func (p *Aliyun) DescribeRedisInstances() (insts []RedisInstance, err error) {
+	return p.listRedisInstances(nil)
+}
+
+func (p *Aliyun) CreateRedisInstances() (err error) {
+
+	redisesConf := p.Config.GetConfig("aliyun.redis")
+
+	if redisesConf.IsEmpty() {
+		return
+	}
+
+	var createdIds, createdNames []string
+
+	for _, redisName := range redisesConf.Keys() {
+
+		redisConf := redisesConf.GetConfig(redisName)
+
+		var inst *RedisInstance
+		inst, err = p.FindRedisInstance(redisName)
+		if err != nil {
+			return
+		}
+
+		if inst != nil {
+			logrus.WithField("CODE", p.Code).
+				WithField("REDIS-INSTANCE-ID", inst.InstanceId).
+				WithField("REDIS-INSTANCE-NAME", redisName).Infoln("Redis instance already created")
+			continue
+		}
+
+		vpcName := redisConf.GetString("vpc-name")
+		vSwitchName := redisConf.GetString("vswitch-name")
+
+		if len(vpcName) == 0 || len(vSwitchName) == 0 {
+			err = fmt.Errorf("redis config of %s's vpc-name or vswitch-name is empty", redisName)
+			return
+		}
+
+		var vSwitch *vpc.VSwitch
+		vSwitch, err = p.FindVSwitch(vpcName, vSwitchName)
+		if err != nil {
+			return
+		}
+
+		if vSwitch == nil {
+			err = fmt.Errorf("redis instance of %s vswitch is not found", redisName)
+			return
+		}
+
+		var password, fnName string
+		password, fnName, err = p.tryInvokeEnvFunc(fmt.Sprintf("redis.%s.password", redisName), redisConf.GetString("password"))
+		if err != nil {
+			return
+		}
+
+		if len(password) == 0 {
+			err = fmt.Errorf("redis config of %s's password is empty", redisName)
+			return
+		}
+
+		req := r_kvstore.CreateCreateInstanceRequest()
+
+		req.RegionId = p.Region
+		req.InstanceName = redisName
+		req.InstanceType = "Redis"
+		req.InstanceClass = redisConf.GetString("instance-class", "redis.master.small.default")
+		req.EngineVersion = redisConf.GetString("engine-version", "4.0")
+		req.ChargeType = redisConf.GetString("charge-type", "PostPaid")
+		req.NetworkType = "VPC"
+		req.VpcId = vSwitch.VpcId
+		req.VSwitchId = vSwitch.VSwitchId
+		req.ZoneId = redisConf.GetString("zone-id", vSwitch.ZoneId)
+		req.PrivateIpAddress = redisConf.GetString("private-ip-address")
+		req.Password = password
+
+		var resp *r_kvstore.CreateInstanceResponse
+		resp, err = p.RedisClient().CreateInstance(req)
+		if err != nil {
+			err = fmt.Errorf("create redis instance '%s' failure: %s", redisName, err.Error())
+			return
+		}
+
+		// the instance without tags could not be found by name, report its id to clean it up
+		err = p.addRedisInstanceTags(resp.InstanceId, redisName)
+		if err != nil {
+			err = fmt.Errorf("tag created redis instance '%s' (%s) failure, it should be tagged or deleted manually: %s", redisName, resp.InstanceId, err.Error())
+			return
+		}
+
+		if len(fnName) > 0 {
+			setENV(fmt.Sprintf("redis_%s_password", redisName), password)
+		}
+
+		createdIds = append(createdIds, resp.InstanceId)
+		createdNames = append(createdNames, redisName)
+
+		logrus.WithField("CODE", p.Code).
+			WithField("REDIS-INSTANCE-ID", resp.InstanceId).
+			WithField("REDIS-INSTANCE-NAME", redisName).
+			WithField("REDIS-CONN-STR", resp.ConnectionDomain).
+			WithField("REDIS-VSWITCH-ID", req.VSwitchId).
+			Infoln("Redis instance created")
+	}
+
+	// the whitelist could not be modified before the instance is normal
+	err = p.waitResources(context.Background(), "redis", createdIds, createdNames, func(ctx context.Context, instId string) error {
+		return p.WaitForRedisInstanceContext(ctx, instId, "Normal", 60*20)
+	})
+	if err != nil {
+		return
+	}
+
+	err = p.SyncRedisWhitelist()
+
+	return
+}
+
+func (p *Aliyun) addRedisInstanceTags(instanceId, name string) (err error) {
+
+	req := r_kvstore.CreateTagResourcesRequest()
+
+	req.RegionId = p.Region
+	req.ResourceType = "INSTANCE"
+	req.ResourceId = &[]string{instanceId}
+	req.Tag = &[]r_kvstore.TagResourcesTag{
+		{Key: "code", Value: p.Code},
+		{Key: "creator", Value: "go-flow"},
+		{Key: "name", Value: name},
+	}
+
+	_, err = p.RedisClient().TagResources(req)
+
+	return
+}
+
+func (p *Aliyun) SyncRedisWhitelist() (err error) {
+
+	redisesConf := p.Config.GetConfig("aliyun.redis")
+
+	for _, redisName := range redisesConf.Keys() {
+
+		redisConf := redisesConf.GetConfig(redisName)
+
+		whitelistConf := redisConf.GetConfig("whitelist")
+
+		if whitelistConf.IsEmpty() {
+			continue
+		}
+
+		var inst *RedisInstance
+		inst, err = p.FindRedisInstance(redisName)
+		if err != nil {
+			return
+		}
+
+		if inst == nil {
+			err = fmt.Errorf("redis instance of %s not exist", redisName)
+			return
+		}
+
+		err = p.syncWhitelistGroups("redis "+redisName, redisConf.GetString("vpc-name"), whitelistConf, nil,
+			func(groupName, securityIps string) error {
+
+				req := r_kvstore.CreateModifySecurityIpsRequest()
+
+				req.InstanceId = inst.InstanceId
+				req.SecurityIpGroupName = groupName
+				req.SecurityIps = securityIps
+				req.ModifyMode = "Cover"
+
+				_, e := p.RedisClient().ModifySecurityIps(req)
+
+				return e
+			},
+			func() error {
+				return p.WaitForRedisInstance(inst.InstanceId, "Normal", 300)
+			},
+		)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (p *Aliyun) DeleteRedisInstances() (err error) {
+
+	redisesConf := p.Config.GetConfig("aliyun.redis")
+
+	for _, redisName := range redisesConf.Keys() {
+
+		var inst *RedisInstance
+		inst, err = p.FindRedisInstance(redisName)
+		if err != nil {
+			return
+		}
+
+		if inst == nil {
+			continue
+		}
+
+		req := r_kvstore.CreateDeleteInstanceRequest()
+		req.InstanceId = inst.InstanceId
+
+		_, err = p.RedisClient().DeleteInstance(req)
+		if err != nil {
+			err = fmt.Errorf("delete redis instance '%s' failure: %s", redisName, err.Error())
+			return
+		}
+
+		logrus.WithField("CODE", p.Code).
+			WithField("REDIS-INSTANCE-ID", inst.InstanceId).
+			WithField("REDIS-INSTANCE-NAME", redisName).Infoln("Redis instance deleted")
+	}
+
+	return
+}
+
+func (p *Aliyun) redisInstanceStatus(instanceId string) (status string, err error) {
+
+	req := r_kvstore.CreateDescribeInstancesRequest()
+
+	req.RegionId = p.Region
+	req.InstanceIds = instanceId
+
+	resp, err := p.RedisClient().DescribeInstances(req)
+	if err != nil {
+		return
+	}
+
+	if len(resp.Instances.KVStoreInstance) == 0 {
+		status = WaitNotFound
+		return
+	}
+
+	status = resp.Instances.KVStoreInstance[0].InstanceStatus
+
+	return
+}
+
+func (p *Aliyun) WaitForRedisInstance(instanceId, status string, timeout int) error {
+	return p.WaitForRedisInstanceContext(context.Background(), instanceId, status, timeout)
+}
+
+func (p *Aliyun) WaitForRedisInstanceContext(ctx context.Context, instanceId, status string, timeout int) error {
+
+	if timeout <= 0 {
+		timeout = 120
+	}
+
+	waiter := p.newWaiter(fmt.Sprintf("redis instance %s to %s", instanceId, status), time.Duration(timeout)*time.Second,
+		[]string{status}, terminalStatusExcept(redisTerminalStatus, status)...)
+	waiter.Fields["REDIS-INSTANCE-ID"] = instanceId
+
+	return waiter.Wait(ctx, func() (string, error) {
+		return p.redisInstanceStatus(instanceId)
+	})
+}
+
+// WaitForRedisInstances waits for all redis instances of code to given status concurrently
+func (p *Aliyun) WaitForRedisInstances(ctx context.Context, status string, timeout int) (err error) {
+
+	insts, err := p.listRedisInstances(nil)
+	if err != nil {
+		return
+	}
+
+	var instIds, names []string
+
+	for _, inst := range insts {
+		instIds = append(instIds, inst.InstanceId)
+		names = append(names, inst.Name)
+	}
+
+	err = p.waitResources(ctx, "redis", instIds, names, func(ctx context.Context, instId string) error {
+		return p.WaitForRedisInstanceContext(ctx, instId, status, timeout)
+	})
+
+	return
+}
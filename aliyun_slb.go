@@ -1,7 +1,10 @@
 package aliyun
 
 import (
+	"context"
 	"fmt"
+	"time"
+
 	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
 
 	"github.com/sirupsen/logrus"
@@ -253,3 +256,57 @@ func (p *Aliyun) DeleteLoadBalancer() (err error) {
 
 	return
 }
+
+func (p *Aliyun) WaitForAllLoadBalancerActive(ctx context.Context, timeout int) (err error) {
+
+	lbs, err := p.ListLoadBalancers(false)
+	if err != nil {
+		return
+	}
+
+	balancersConfig := p.Config.GetConfig("aliyun.slb.balancer")
+
+	var waits []func(ctx context.Context) error
+
+	for _, slbName := range balancersConfig.Keys() {
+
+		lb, exist := lbs[slbName]
+		if !exist {
+			err = fmt.Errorf("slb %s not found", slbName)
+			return
+		}
+
+		lbId := lb.LoadBalancerId
+
+		waiter := p.newWaiter(fmt.Sprintf("slb '%s' active", slbName), time.Duration(timeout)*time.Second, []string{"active"}, "locked")
+		waiter.Fields["SLB-NAME"] = slbName
+		waiter.Fields["SLB-ID"] = lbId
+
+		waits = append(waits, func(ctx context.Context) error {
+			return waiter.Wait(ctx, func() (status string, err error) {
+				req := slb.CreateDescribeLoadBalancerAttributeRequest()
+				req.LoadBalancerId = lbId
+
+				var resp *slb.DescribeLoadBalancerAttributeResponse
+				resp, err = p.SLBClient().DescribeLoadBalancerAttribute(req)
+
+				if IsAliErrCode(err, "InvalidLoadBalancerId.NotFound") {
+					return WaitNotFound, nil
+				}
+
+				if err != nil {
+					return
+				}
+
+				status = resp.LoadBalancerStatus
+				return
+			})
+		})
+	}
+
+	logrus.WithField("CODE", p.Code).Infoln("Wait for all SLB active")
+
+	err = p.waitAll(ctx, waits...)
+
+	return
+}
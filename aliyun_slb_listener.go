@@ -3,6 +3,7 @@ package aliyun
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
 	"github.com/aliyun/alibaba-cloud-sdk-go/services/slb"
@@ -30,6 +31,31 @@ func (p *Aliyun) startSLBListener(loadBalancerId string, port requests.Integer)
 	return
 }
 
+// slbListenerKeys returns the listeners of the balancer keyed by lower case protocol and port, e.g. tcp/80,
+// the udp and tcp listeners could listen the same port
+func slbListenerKeys(lb *SLBLoadBalancer) map[string]bool {
+
+	keys := make(map[string]bool)
+
+	for _, item := range lb.ListenerPortsAndProtocol.ListenerPortAndProtocol {
+		keys[strings.ToLower(item.ListenerProtocol)+"/"+strconv.Itoa(item.ListenerPort)] = true
+	}
+
+	return keys
+}
+
+// slbListenedPorts returns the ports of the balancer listened by protocol
+func slbListenedPorts(lb *SLBLoadBalancer, protocol string) (ports []string) {
+
+	for _, item := range lb.ListenerPortsAndProtocol.ListenerPortAndProtocol {
+		if strings.ToLower(item.ListenerProtocol) == protocol {
+			ports = append(ports, strconv.Itoa(item.ListenerPort))
+		}
+	}
+
+	return
+}
+
 func (p *Aliyun) CreateLoadBalancerHTTPListener() (err error) {
 	currentLBSs, err := p.ListLoadBalancers(true)
 	if err != nil {
@@ -100,6 +126,12 @@ func (p *Aliyun) CreateLoadBalancerHTTPListener() (err error) {
 				continue
 			}
 
+			var vGroupId string
+			vGroupId, _, err = p.slbListenerServerGroups(slbInstance, "http", listenerConfig)
+			if err != nil {
+				return
+			}
+
 			req := slb.CreateCreateLoadBalancerHTTPListenerRequest()
 
 			// serverCertificateId: listenerConfig.GetString("server-certificate-id"),
@@ -123,7 +155,7 @@ func (p *Aliyun) CreateLoadBalancerHTTPListener() (err error) {
 			req.HealthCheckTimeout = requests.NewInteger(int(listenerConfig.GetInt64("health-check.timeout", 5)))
 			req.HealthCheckInterval = requests.NewInteger(int(listenerConfig.GetInt64("health-check.interval", 2)))
 			req.HealthCheckHttpCode = listenerConfig.GetString("health-check.http-code", "http_2xx")
-			req.VServerGroupId = listenerConfig.GetString("vserver-group-id")
+			req.VServerGroupId = vGroupId
 			req.XForwardedForSLBID = listenerConfig.GetString("x-forward-for-slb-id", "on")
 			req.XForwardedForSLBIP = listenerConfig.GetString("x-forward-for-slb-ip", "on")
 			req.XForwardedForProto = listenerConfig.GetString("x-forward-for-proto", "on")
@@ -253,6 +285,12 @@ func (p *Aliyun) CreateLoadBalancerHTTPSListener() (err error) {
 				}
 			}
 
+			var vGroupId string
+			vGroupId, _, err = p.slbListenerServerGroups(slbInstance, "https", listenerConfig)
+			if err != nil {
+				return
+			}
+
 			req := slb.CreateCreateLoadBalancerHTTPSListenerRequest()
 
 			req.ServerCertificateId = srvCertId
@@ -276,7 +314,7 @@ func (p *Aliyun) CreateLoadBalancerHTTPSListener() (err error) {
 			req.HealthCheckTimeout = requests.NewInteger(int(listenerConfig.GetInt64("health-check.timeout", 5)))
 			req.HealthCheckInterval = requests.NewInteger(int(listenerConfig.GetInt64("health-check.interval", 2)))
 			req.HealthCheckHttpCode = listenerConfig.GetString("health-check.http-code", "http_2xx")
-			req.VServerGroupId = listenerConfig.GetString("vserver-group-id")
+			req.VServerGroupId = vGroupId
 			req.XForwardedForSLBID = listenerConfig.GetString("x-forward-for-slb-id", "on")
 			req.XForwardedForSLBIP = listenerConfig.GetString("x-forward-for-slb-ip", "on")
 			req.XForwardedForProto = listenerConfig.GetString("x-forward-for-proto", "on")
@@ -376,6 +414,12 @@ func (p *Aliyun) CreateLoadBalancerTCPListener() (err error) {
 				continue
 			}
 
+			var vGroupId, msGroupId string
+			vGroupId, msGroupId, err = p.slbListenerServerGroups(slbInstance, "tcp", listenerConfig)
+			if err != nil {
+				return
+			}
+
 			req := slb.CreateCreateLoadBalancerTCPListenerRequest()
 
 			// Common part
@@ -390,7 +434,8 @@ func (p *Aliyun) CreateLoadBalancerTCPListener() (err error) {
 			req.UnhealthyThreshold = requests.NewInteger(int(listenerConfig.GetInt64("health-check.unhealthy-threshold", 3)))
 			req.HealthCheckConnectTimeout = requests.NewInteger(int(listenerConfig.GetInt64("health-check.timeout", 5)))
 			req.HealthCheckInterval = requests.NewInteger(int(listenerConfig.GetInt64("health-check.interval", 2)))
-			req.VServerGroupId = listenerConfig.GetString("vserver-group-id")
+			req.VServerGroupId = vGroupId
+			req.MasterSlaveServerGroupId = msGroupId
 
 			// TCP Part
 			req.HealthCheckType = listenerConfig.GetString("health-check.type", "tcp")
@@ -493,6 +538,12 @@ func (p *Aliyun) CreateLoadBalancerUDPListener() (err error) {
 				continue
 			}
 
+			var vGroupId, msGroupId string
+			vGroupId, msGroupId, err = p.slbListenerServerGroups(slbInstance, "udp", listenerConfig)
+			if err != nil {
+				return
+			}
+
 			req := slb.CreateCreateLoadBalancerUDPListenerRequest()
 
 			// Common part
@@ -507,7 +558,8 @@ func (p *Aliyun) CreateLoadBalancerUDPListener() (err error) {
 			req.UnhealthyThreshold = requests.NewInteger(int(listenerConfig.GetInt64("health-check.unhealthy-threshold", 3)))
 			req.HealthCheckConnectTimeout = requests.NewInteger(int(listenerConfig.GetInt64("health-check.timeout", 5)))
 			req.HealthCheckInterval = requests.NewInteger(int(listenerConfig.GetInt64("health-check.interval", 2)))
-			req.VServerGroupId = listenerConfig.GetString("vserver-group-id")
+			req.VServerGroupId = vGroupId
+			req.MasterSlaveServerGroupId = msGroupId
 
 			reqs = append(reqs, req)
 		}
@@ -570,7 +622,7 @@ func (p *Aliyun) ListSLBHTTPListeners() (listerners map[string][]*slb.DescribeLo
 			continue
 		}
 
-		for _, port := range slbInstance.ListenerPorts.ListenerPort {
+		for _, port := range slbListenedPorts(slbInstance, "http") {
 
 			req := slb.CreateDescribeLoadBalancerHTTPListenerAttributeRequest()
 
@@ -629,7 +681,7 @@ func (p *Aliyun) ListSLBHTTPSListeners() (listerners map[string][]*slb.DescribeL
 			continue
 		}
 
-		for _, port := range slbInstance.ListenerPorts.ListenerPort {
+		for _, port := range slbListenedPorts(slbInstance, "https") {
 
 			req := slb.CreateDescribeLoadBalancerHTTPSListenerAttributeRequest()
 
@@ -688,7 +740,7 @@ func (p *Aliyun) ListSLBTCPListeners() (listerners map[string][]*slb.DescribeLoa
 			continue
 		}
 
-		for _, port := range slbInstance.ListenerPorts.ListenerPort {
+		for _, port := range slbListenedPorts(slbInstance, "tcp") {
 
 			req := slb.CreateDescribeLoadBalancerTCPListenerAttributeRequest()
 
@@ -741,13 +793,13 @@ func (p *Aliyun) ListSLBUDPListeners() (listerners map[string][]*slb.DescribeLoa
 
 		lbConfig := balancersConfig.GetConfig(slbName)
 
-		listenersConfig := lbConfig.GetConfig("listener.tcp")
+		listenersConfig := lbConfig.GetConfig("listener.udp")
 
 		if listenersConfig.IsEmpty() {
 			continue
 		}
 
-		for _, port := range slbInstance.ListenerPorts.ListenerPort {
+		for _, port := range slbListenedPorts(slbInstance, "udp") {
 
 			req := slb.CreateDescribeLoadBalancerUDPListenerAttributeRequest()
 
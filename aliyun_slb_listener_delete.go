@@ -0,0 +1,234 @@
+package aliyun
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/slb"
+
+	"github.com/sirupsen/logrus"
+)
+
+var slbListenerProtocolNames = []string{"http", "https", "tcp", "udp"}
+
+type slbListenerTarget struct {
+	SLBName      string
+	ListenerName string
+	Protocol     string
+	Port         int
+	Balancer     *SLBLoadBalancer
+}
+
+// slbListenerTargets returns the listened listeners of action (stop or delete) on each balancer, the listeners
+// declared in config are selected while aliyun.slb.balancer.<name>.listener-declared.<action> is true (default),
+// and the listeners absent from config are selected while aliyun.slb.balancer.<name>.listener-prune.<action> is true,
+// e.g. only prune the undeclared listeners with listener-declared.delete = false and listener-prune.delete = true
+func (p *Aliyun) slbListenerTargets(action string) (targets []slbListenerTarget, err error) {
+
+	balancersConfig := p.Config.GetConfig("aliyun.slb.balancer")
+
+	slbNames := balancersConfig.Keys()
+
+	if len(slbNames) == 0 {
+		return
+	}
+
+	currentLBSs, err := p.ListLoadBalancers(true)
+	if err != nil {
+		return
+	}
+
+	for _, slbName := range slbNames {
+
+		slbInstance, exist := currentLBSs[slbName]
+
+		if !exist {
+			continue
+		}
+
+		declared := balancersConfig.GetBoolean(slbName+".listener-declared."+action, true)
+		prune := balancersConfig.GetBoolean(slbName+".listener-prune."+action, false)
+
+		if !declared && !prune {
+			continue
+		}
+
+		var configuredListeners = make(map[string]string) // protocol/port:listener name
+
+		for _, protocol := range slbListenerProtocolNames {
+
+			listenersConfig := balancersConfig.GetConfig(slbName + ".listener." + protocol)
+
+			for _, listenerName := range listenersConfig.Keys() {
+				listenPort := listenersConfig.GetConfig(listenerName).GetInt32("listen-port")
+				configuredListeners[protocol+"/"+strconv.Itoa(int(listenPort))] = listenerName
+			}
+		}
+
+		for _, item := range slbInstance.ListenerPortsAndProtocol.ListenerPortAndProtocol {
+
+			protocol := strings.ToLower(item.ListenerProtocol)
+
+			listenerName, configured := configuredListeners[protocol+"/"+strconv.Itoa(item.ListenerPort)]
+
+			if (configured && !declared) || (!configured && !prune) {
+				continue
+			}
+
+			targets = append(targets, slbListenerTarget{
+				SLBName:      slbName,
+				ListenerName: listenerName,
+				Protocol:     protocol,
+				Port:         item.ListenerPort,
+				Balancer:     slbInstance,
+			})
+		}
+	}
+
+	return
+}
+
+// stopSLBListener stops the listener of protocol and port, the protocol is required while the tcp
+// and udp listeners listen the same port
+func (p *Aliyun) stopSLBListener(loadBalancerId, protocol string, port requests.Integer) (err error) {
+
+	req := slb.CreateStopLoadBalancerListenerRequest()
+
+	req.LoadBalancerId = loadBalancerId
+	req.RegionId = p.Region
+	req.ListenerPort = port
+	req.ListenerProtocol = protocol
+
+	_, err = p.SLBClient().StopLoadBalancerListener(req)
+	if err != nil {
+		return
+	}
+
+	logrus.WithField("CODE", p.Code).
+		WithField("SLB-ID", loadBalancerId).
+		WithField("PROTOCOL", protocol).
+		WithField("PORT", port).Infoln("Listener stopped")
+
+	return
+}
+
+func (p *Aliyun) deleteSLBListenerRules(loadBalancerId string, port requests.Integer) (err error) {
+
+	describeReq := slb.CreateDescribeRulesRequest()
+
+	describeReq.RegionId = p.Region
+	describeReq.LoadBalancerId = loadBalancerId
+	describeReq.ListenerPort = port
+
+	resp, err := p.SLBClient().DescribeRules(describeReq)
+	if err != nil {
+		return
+	}
+
+	if len(resp.Rules.Rule) == 0 {
+		return
+	}
+
+	var ruleIds []string
+
+	for _, rule := range resp.Rules.Rule {
+		ruleIds = append(ruleIds, rule.RuleId)
+	}
+
+	data, err := json.Marshal(ruleIds)
+	if err != nil {
+		return
+	}
+
+	req := slb.CreateDeleteRulesRequest()
+
+	req.RegionId = p.Region
+	req.RuleIds = string(data)
+
+	_, err = p.SLBClient().DeleteRules(req)
+	if err != nil {
+		return
+	}
+
+	logrus.WithField("CODE", p.Code).
+		WithField("SLB-ID", loadBalancerId).
+		WithField("PORT", port).
+		WithField("RULE-IDS", ruleIds).Infoln("Listener rules deleted")
+
+	return
+}
+
+// StopLoadBalancerListeners stops the listeners selected by listener-declared.stop and listener-prune.stop
+func (p *Aliyun) StopLoadBalancerListeners() (err error) {
+
+	targets, err := p.slbListenerTargets("stop")
+	if err != nil {
+		return
+	}
+
+	for _, target := range targets {
+
+		err = p.stopSLBListener(target.Balancer.LoadBalancerId, target.Protocol, requests.NewInteger(target.Port))
+		if err != nil {
+			err = fmt.Errorf("stop listener %d of slb %s failure: %s", target.Port, target.SLBName, err.Error())
+			return
+		}
+	}
+
+	return
+}
+
+// DeleteLoadBalancerListeners deletes the listeners selected by listener-declared.delete and listener-prune.delete,
+// the listener is stopped and its rules are deleted first
+func (p *Aliyun) DeleteLoadBalancerListeners() (err error) {
+
+	targets, err := p.slbListenerTargets("delete")
+	if err != nil {
+		return
+	}
+
+	for _, target := range targets {
+
+		loadBalancerId := target.Balancer.LoadBalancerId
+		port := requests.NewInteger(target.Port)
+
+		err = p.stopSLBListener(loadBalancerId, target.Protocol, port)
+		if err != nil {
+			err = fmt.Errorf("stop listener %d of slb %s failure: %s", target.Port, target.SLBName, err.Error())
+			return
+		}
+
+		if target.Protocol == "http" || target.Protocol == "https" {
+			err = p.deleteSLBListenerRules(loadBalancerId, port)
+			if err != nil {
+				err = fmt.Errorf("delete rules of listener %d of slb %s failure: %s", target.Port, target.SLBName, err.Error())
+				return
+			}
+		}
+
+		req := slb.CreateDeleteLoadBalancerListenerRequest()
+
+		req.RegionId = p.Region
+		req.LoadBalancerId = loadBalancerId
+		req.ListenerPort = port
+		req.ListenerProtocol = target.Protocol
+
+		_, err = p.SLBClient().DeleteLoadBalancerListener(req)
+		if err != nil {
+			err = fmt.Errorf("delete listener %d of slb %s failure: %s", target.Port, target.SLBName, err.Error())
+			return
+		}
+
+		logrus.WithField("CODE", p.Code).
+			WithField("SLB-NAME", target.SLBName).
+			WithField("SLB-ID", loadBalancerId).
+			WithField("SLB-LISTENER", target.ListenerName).
+			WithField("PROTOCOL", target.Protocol).
+			WithField("PORT", target.Port).Infoln("Listener deleted")
+	}
+
+	return
+}
@@ -0,0 +1,480 @@
+package aliyun
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/slb"
+	"github.com/gogap/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+// slbListenerDiff collects the listener attributes which are different from config, the attributes not
+// configured are compared with the defaults of creating listener, so that config fully describes the listener
+type slbListenerDiff struct {
+	conf    config.Configuration
+	changes []string
+
+	vServerGroupId           string
+	masterSlaveServerGroupId string
+}
+
+func (p *slbListenerDiff) Value(key, current, desired string, set *string) {
+	if current == desired {
+		return
+	}
+
+	*set = desired
+	p.changes = append(p.changes, fmt.Sprintf("%s: '%s' -> '%s'", key, current, desired))
+}
+
+func (p *slbListenerDiff) String(key, def, current string, set *string) {
+
+	desired := p.conf.GetString(key, def)
+
+	// the empty value is not sent to the api, the attribute is kept as it is
+	if len(desired) == 0 {
+		return
+	}
+
+	p.Value(key, current, desired, set)
+}
+
+func (p *slbListenerDiff) Integer(key string, def, current int, set *requests.Integer) {
+
+	desired := int(p.conf.GetInt64(key, int64(def)))
+
+	if current == desired {
+		return
+	}
+
+	*set = requests.NewInteger(desired)
+	p.changes = append(p.changes, fmt.Sprintf("%s: %d -> %d", key, current, desired))
+}
+
+// VServerGroup switches the vserver group on with the group of config, or off while no group configured
+func (p *slbListenerDiff) VServerGroup(current string, setOn, setId *string) {
+	p.serverGroup("vserver-group-id", p.vServerGroupId, current, setOn, setId)
+}
+
+// MasterSlaveServerGroup switches the master slave server group on with the group of config, or off while no group configured
+func (p *slbListenerDiff) MasterSlaveServerGroup(current string, setOn, setId *string) {
+	p.serverGroup("master-slave-server-group-id", p.masterSlaveServerGroupId, current, setOn, setId)
+}
+
+func (p *slbListenerDiff) serverGroup(key, desired, current string, setOn, setId *string) {
+	if desired == current {
+		return
+	}
+
+	if len(desired) == 0 {
+		*setOn = "off"
+		p.changes = append(p.changes, fmt.Sprintf("%s: '%s' -> off", key, current))
+		return
+	}
+
+	*setOn = "on"
+	p.Value(key, current, desired, setId)
+}
+
+func (p *slbListenerDiff) Changed() bool {
+	return len(p.changes) > 0
+}
+
+// updateSLBListeners walks the listeners of protocol in config, the listener not created yet would be skipped,
+// and the listener port which is listened by another protocol would be an error, except the udp and tcp on same port
+func (p *Aliyun) updateSLBListeners(protocol string, update func(lb *SLBLoadBalancer, port int32, diff *slbListenerDiff) error) (err error) {
+
+	balancersConfig := p.Config.GetConfig("aliyun.slb.balancer")
+
+	slbNames := balancersConfig.Keys()
+
+	if len(slbNames) == 0 {
+		return
+	}
+
+	currentLBSs, err := p.ListLoadBalancers(true)
+	if err != nil {
+		return
+	}
+
+	for _, slbName := range slbNames {
+
+		slbInstance, exist := currentLBSs[slbName]
+
+		if !exist {
+			err = fmt.Errorf("slb of %s not exist", slbName)
+			return
+		}
+
+		listenersConfig := balancersConfig.GetConfig(slbName + ".listener." + protocol)
+
+		if listenersConfig.IsEmpty() {
+			continue
+		}
+
+		listened := slbListenerKeys(slbInstance)
+
+		for _, listenerName := range listenersConfig.Keys() {
+
+			listenerConfig := listenersConfig.GetConfig(listenerName)
+
+			listenPort := listenerConfig.GetInt32("listen-port")
+
+			if listenPort <= 0 {
+				err = fmt.Errorf("listen port is not correct, listener: %s.%s", slbName, listenerName)
+				return
+			}
+
+			port := strconv.Itoa(int(listenPort))
+
+			if !listened[protocol+"/"+port] {
+
+				// udp listener shares the port with the tcp based listener, the others could not
+				for _, other := range slbListenerProtocolNames {
+					if other != protocol && (other == "udp") == (protocol == "udp") && listened[other+"/"+port] {
+						err = fmt.Errorf("port %d of slb %s is listened by %s, could not update to %s, listener: %s", listenPort, slbName, other, protocol, listenerName)
+						return
+					}
+				}
+
+				logrus.WithField("CODE", p.Code).
+					WithField("SLB-NAME", slbName).
+					WithField("SLB-LISTENER", listenerName).
+					WithField("PORT", listenPort).Warnln("Listener not created, skip update")
+				continue
+			}
+
+			diff := &slbListenerDiff{conf: listenerConfig}
+
+			diff.vServerGroupId, diff.masterSlaveServerGroupId, err = p.slbListenerServerGroups(slbInstance, protocol, listenerConfig)
+			if err != nil {
+				return
+			}
+
+			err = update(slbInstance, listenPort, diff)
+			if err != nil {
+				err = fmt.Errorf("update %s listener %s.%s failure: %s", protocol, slbName, listenerName, err.Error())
+				return
+			}
+
+			if !diff.Changed() {
+				logrus.WithField("CODE", p.Code).
+					WithField("SLB-NAME", slbName).
+					WithField("SLB-LISTENER", listenerName).
+					WithField("PORT", listenPort).Infoln("Listener not changed")
+				continue
+			}
+
+			logrus.WithField("CODE", p.Code).
+				WithField("SLB-NAME", slbName).
+				WithField("SLB-ID", slbInstance.LoadBalancerId).
+				WithField("SLB-LISTENER", listenerName).
+				WithField("PORT", listenPort).
+				WithField("CHANGES", strings.Join(diff.changes, ", ")).Infof("SLB %s listener updated", protocol)
+		}
+	}
+
+	return
+}
+
+func (p *Aliyun) UpdateLoadBalancerHTTPListener() (err error) {
+	return p.updateSLBListeners("http", func(lb *SLBLoadBalancer, port int32, diff *slbListenerDiff) (err error) {
+
+		descReq := slb.CreateDescribeLoadBalancerHTTPListenerAttributeRequest()
+
+		descReq.LoadBalancerId = lb.LoadBalancerId
+		descReq.Port = strconv.Itoa(int(port))
+
+		current, err := p.SLBClient().DescribeLoadBalancerHTTPListenerAttribute(descReq)
+		if err != nil {
+			return
+		}
+
+		req := slb.CreateSetLoadBalancerHTTPListenerAttributeRequest()
+
+		// the band width of the pay by traffic balancer is -1, it is kept as it is while not configured
+		diff.Integer("band-width", current.Bandwidth, current.Bandwidth, &req.Bandwidth)
+		diff.String("scheduler", "wrr", current.Scheduler, &req.Scheduler)
+		diff.String("gzip", "on", current.Gzip, &req.Gzip)
+		diff.String("sticky-session", "off", current.StickySession, &req.StickySession)
+
+		stickySession := diff.conf.GetString("sticky-session", "off")
+		stickySessionType := diff.conf.GetString("sticky-session-type", "insert")
+
+		// the session attributes are only accepted while sticky session is on
+		if stickySession == "on" {
+			diff.String("sticky-session-type", "insert", current.StickySessionType, &req.StickySessionType)
+
+			if stickySessionType == "insert" {
+				diff.Integer("cookie-timeout", 86400, current.CookieTimeout, &req.CookieTimeout)
+			} else {
+				diff.String("cookie", "", current.Cookie, &req.Cookie)
+			}
+		}
+
+		diff.String("health-check.check", "on", current.HealthCheck, &req.HealthCheck)
+
+		healthCheck := diff.conf.GetString("health-check.check", "on")
+
+		// the health check attributes are only accepted while health check is on
+		if healthCheck == "on" {
+			diff.String("health-check.domain", "", current.HealthCheckDomain, &req.HealthCheckDomain)
+			diff.String("health-check.url", "", current.HealthCheckURI, &req.HealthCheckURI)
+			diff.Integer("health-check.connect-port", int(port), current.HealthCheckConnectPort, &req.HealthCheckConnectPort)
+			diff.Integer("health-check.threshold", 3, current.HealthyThreshold, &req.HealthyThreshold)
+			diff.Integer("health-check.unhealthy-threshold", 3, current.UnhealthyThreshold, &req.UnhealthyThreshold)
+			diff.Integer("health-check.timeout", 5, current.HealthCheckTimeout, &req.HealthCheckTimeout)
+			diff.Integer("health-check.interval", 2, current.HealthCheckInterval, &req.HealthCheckInterval)
+			diff.String("health-check.http-code", "http_2xx", current.HealthCheckHttpCode, &req.HealthCheckHttpCode)
+		}
+
+		diff.VServerGroup(current.VServerGroupId, &req.VServerGroup, &req.VServerGroupId)
+		diff.String("x-forward-for-slb-id", "on", current.XForwardedForSLBID, &req.XForwardedForSLBID)
+		diff.String("x-forward-for-slb-ip", "on", current.XForwardedForSLBIP, &req.XForwardedForSLBIP)
+		diff.String("x-forward-for-proto", "on", current.XForwardedForProto, &req.XForwardedForProto)
+
+		if !diff.Changed() {
+			return
+		}
+
+		// sticky-session and health-check are required by the api, so are the session attributes while sticky session is on
+		req.StickySession = stickySession
+		req.HealthCheck = healthCheck
+
+		if stickySession == "on" {
+			req.StickySessionType = stickySessionType
+
+			if stickySessionType == "insert" {
+				req.CookieTimeout = requests.NewInteger(int(diff.conf.GetInt64("cookie-timeout", 86400)))
+			} else {
+				req.Cookie = diff.conf.GetString("cookie")
+			}
+		}
+
+		req.LoadBalancerId = lb.LoadBalancerId
+		req.ListenerPort = requests.NewInteger(int(port))
+
+		_, err = p.SLBClient().SetLoadBalancerHTTPListenerAttribute(req)
+
+		return
+	})
+}
+
+func (p *Aliyun) UpdateLoadBalancerHTTPSListener() (err error) {
+	return p.updateSLBListeners("https", func(lb *SLBLoadBalancer, port int32, diff *slbListenerDiff) (err error) {
+
+		descReq := slb.CreateDescribeLoadBalancerHTTPSListenerAttributeRequest()
+
+		descReq.LoadBalancerId = lb.LoadBalancerId
+		descReq.Port = strconv.Itoa(int(port))
+
+		current, err := p.SLBClient().DescribeLoadBalancerHTTPSListenerAttribute(descReq)
+		if err != nil {
+			return
+		}
+
+		req := slb.CreateSetLoadBalancerHTTPSListenerAttributeRequest()
+
+		srvCertId := diff.conf.GetString("server-certificate-id")
+
+		if len(srvCertId) == 0 {
+			if srvCertName := diff.conf.GetString("server-certificate-name"); len(srvCertName) > 0 {
+				srvCertId, err = p.getSLBServerCertByName(srvCertName)
+				if err != nil {
+					return
+				}
+			}
+		}
+
+		if len(srvCertId) > 0 {
+			diff.Value("server-certificate-id", current.ServerCertificateId, srvCertId, &req.ServerCertificateId)
+		}
+
+		caCertId := diff.conf.GetString("ca-certificate-id")
+
+		if len(caCertId) == 0 {
+			if caCertName := diff.conf.GetString("ca-certificate-name"); len(caCertName) > 0 {
+				caCertId, err = p.getSLBCACertByName(caCertName)
+				if err != nil {
+					return
+				}
+			}
+		}
+
+		if len(caCertId) > 0 {
+			diff.Value("ca-certificate-id", current.CACertificateId, caCertId, &req.CACertificateId)
+		}
+
+		// the band width of the pay by traffic balancer is -1, it is kept as it is while not configured
+		diff.Integer("band-width", current.Bandwidth, current.Bandwidth, &req.Bandwidth)
+		diff.String("scheduler", "wrr", current.Scheduler, &req.Scheduler)
+		diff.String("gzip", "on", current.Gzip, &req.Gzip)
+		diff.String("sticky-session", "off", current.StickySession, &req.StickySession)
+
+		stickySession := diff.conf.GetString("sticky-session", "off")
+		stickySessionType := diff.conf.GetString("sticky-session-type", "insert")
+
+		// the session attributes are only accepted while sticky session is on
+		if stickySession == "on" {
+			diff.String("sticky-session-type", "insert", current.StickySessionType, &req.StickySessionType)
+
+			if stickySessionType == "insert" {
+				diff.Integer("cookie-timeout", 86400, current.CookieTimeout, &req.CookieTimeout)
+			} else {
+				diff.String("cookie", "", current.Cookie, &req.Cookie)
+			}
+		}
+
+		diff.String("health-check.check", "on", current.HealthCheck, &req.HealthCheck)
+
+		healthCheck := diff.conf.GetString("health-check.check", "on")
+
+		// the health check attributes are only accepted while health check is on
+		if healthCheck == "on" {
+			diff.String("health-check.domain", "", current.HealthCheckDomain, &req.HealthCheckDomain)
+			diff.String("health-check.url", "", current.HealthCheckURI, &req.HealthCheckURI)
+			diff.Integer("health-check.connect-port", int(port), current.HealthCheckConnectPort, &req.HealthCheckConnectPort)
+			diff.Integer("health-check.threshold", 3, current.HealthyThreshold, &req.HealthyThreshold)
+			diff.Integer("health-check.unhealthy-threshold", 3, current.UnhealthyThreshold, &req.UnhealthyThreshold)
+			diff.Integer("health-check.timeout", 5, current.HealthCheckTimeout, &req.HealthCheckTimeout)
+			diff.Integer("health-check.interval", 2, current.HealthCheckInterval, &req.HealthCheckInterval)
+			diff.String("health-check.http-code", "http_2xx", current.HealthCheckHttpCode, &req.HealthCheckHttpCode)
+		}
+
+		diff.VServerGroup(current.VServerGroupId, &req.VServerGroup, &req.VServerGroupId)
+		diff.String("x-forward-for-slb-id", "on", current.XForwardedForSLBID, &req.XForwardedForSLBID)
+		diff.String("x-forward-for-slb-ip", "on", current.XForwardedForSLBIP, &req.XForwardedForSLBIP)
+		diff.String("x-forward-for-proto", "on", current.XForwardedForProto, &req.XForwardedForProto)
+
+		if !diff.Changed() {
+			return
+		}
+
+		// sticky-session and health-check are required by the api, so are the session attributes while sticky session is on
+		req.StickySession = stickySession
+		req.HealthCheck = healthCheck
+
+		if stickySession == "on" {
+			req.StickySessionType = stickySessionType
+
+			if stickySessionType == "insert" {
+				req.CookieTimeout = requests.NewInteger(int(diff.conf.GetInt64("cookie-timeout", 86400)))
+			} else {
+				req.Cookie = diff.conf.GetString("cookie")
+			}
+		}
+
+		req.LoadBalancerId = lb.LoadBalancerId
+		req.ListenerPort = requests.NewInteger(int(port))
+
+		_, err = p.SLBClient().SetLoadBalancerHTTPSListenerAttribute(req)
+
+		return
+	})
+}
+
+func (p *Aliyun) UpdateLoadBalancerTCPListener() (err error) {
+	return p.updateSLBListeners("tcp", func(lb *SLBLoadBalancer, port int32, diff *slbListenerDiff) (err error) {
+
+		descReq := slb.CreateDescribeLoadBalancerTCPListenerAttributeRequest()
+
+		descReq.LoadBalancerId = lb.LoadBalancerId
+		descReq.Port = strconv.Itoa(int(port))
+
+		current, err := p.SLBClient().DescribeLoadBalancerTCPListenerAttribute(descReq)
+		if err != nil {
+			return
+		}
+
+		req := slb.CreateSetLoadBalancerTCPListenerAttributeRequest()
+
+		// the band width of the pay by traffic balancer is -1, it is kept as it is while not configured
+		diff.Integer("band-width", current.Bandwidth, current.Bandwidth, &req.Bandwidth)
+		diff.String("scheduler", "wrr", current.Scheduler, &req.Scheduler)
+		diff.Integer("persistence-timeout", 0, current.PersistenceTimeout, &req.PersistenceTimeout)
+		diff.Integer("health-check.connect-port", int(port), current.HealthCheckConnectPort, &req.HealthCheckConnectPort)
+		diff.Integer("health-check.threshold", 3, current.HealthyThreshold, &req.HealthyThreshold)
+		diff.Integer("health-check.unhealthy-threshold", 3, current.UnhealthyThreshold, &req.UnhealthyThreshold)
+		diff.Integer("health-check.timeout", 5, current.HealthCheckConnectTimeout, &req.HealthCheckConnectTimeout)
+		diff.Integer("health-check.interval", 2, current.HealthCheckInterval, &req.HealthCheckInterval)
+		diff.VServerGroup(current.VServerGroupId, &req.VServerGroup, &req.VServerGroupId)
+		diff.MasterSlaveServerGroup(current.MasterSlaveServerGroupId, &req.MasterSlaveServerGroup, &req.MasterSlaveServerGroupId)
+		diff.String("health-check.type", "tcp", current.HealthCheckType, &req.HealthCheckType)
+
+		// the http attributes are only accepted by the http health check
+		if diff.conf.GetString("health-check.type", "tcp") == "http" {
+			diff.String("health-check.domain", "", current.HealthCheckDomain, &req.HealthCheckDomain)
+			diff.String("health-check.url", "", current.HealthCheckURI, &req.HealthCheckURI)
+			diff.String("health-check.http-code", "http_2xx", current.HealthCheckHttpCode, &req.HealthCheckHttpCode)
+		}
+
+		if !diff.Changed() {
+			return
+		}
+
+		// the listener forwards to either vserver group or master slave server group
+		if req.VServerGroup == "on" {
+			req.MasterSlaveServerGroup = "off"
+		} else if req.MasterSlaveServerGroup == "on" {
+			req.VServerGroup = "off"
+		}
+
+		req.LoadBalancerId = lb.LoadBalancerId
+		req.ListenerPort = requests.NewInteger(int(port))
+
+		_, err = p.SLBClient().SetLoadBalancerTCPListenerAttribute(req)
+
+		return
+	})
+}
+
+func (p *Aliyun) UpdateLoadBalancerUDPListener() (err error) {
+	return p.updateSLBListeners("udp", func(lb *SLBLoadBalancer, port int32, diff *slbListenerDiff) (err error) {
+
+		descReq := slb.CreateDescribeLoadBalancerUDPListenerAttributeRequest()
+
+		descReq.LoadBalancerId = lb.LoadBalancerId
+		descReq.Port = strconv.Itoa(int(port))
+
+		current, err := p.SLBClient().DescribeLoadBalancerUDPListenerAttribute(descReq)
+		if err != nil {
+			return
+		}
+
+		req := slb.CreateSetLoadBalancerUDPListenerAttributeRequest()
+
+		// the band width of the pay by traffic balancer is -1, it is kept as it is while not configured
+		diff.Integer("band-width", current.Bandwidth, current.Bandwidth, &req.Bandwidth)
+		diff.String("scheduler", "wrr", current.Scheduler, &req.Scheduler)
+		diff.Integer("persistence-timeout", 0, current.PersistenceTimeout, &req.PersistenceTimeout)
+		diff.Integer("health-check.connect-port", int(port), current.HealthCheckConnectPort, &req.HealthCheckConnectPort)
+		diff.Integer("health-check.threshold", 3, current.HealthyThreshold, &req.HealthyThreshold)
+		diff.Integer("health-check.unhealthy-threshold", 3, current.UnhealthyThreshold, &req.UnhealthyThreshold)
+		diff.Integer("health-check.timeout", 5, current.HealthCheckConnectTimeout, &req.HealthCheckConnectTimeout)
+		diff.Integer("health-check.interval", 2, current.HealthCheckInterval, &req.HealthCheckInterval)
+		diff.VServerGroup(current.VServerGroupId, &req.VServerGroup, &req.VServerGroupId)
+		diff.MasterSlaveServerGroup(current.MasterSlaveServerGroupId, &req.MasterSlaveServerGroup, &req.MasterSlaveServerGroupId)
+
+		if !diff.Changed() {
+			return
+		}
+
+		// the listener forwards to either vserver group or master slave server group
+		if req.VServerGroup == "on" {
+			req.MasterSlaveServerGroup = "off"
+		} else if req.MasterSlaveServerGroup == "on" {
+			req.VServerGroup = "off"
+		}
+
+		req.LoadBalancerId = lb.LoadBalancerId
+		req.ListenerPort = requests.NewInteger(int(port))
+
+		_, err = p.SLBClient().SetLoadBalancerUDPListenerAttribute(req)
+
+		return
+	})
+}
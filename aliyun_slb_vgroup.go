@@ -6,6 +6,7 @@ import (
 
 	"github.com/aliyun/alibaba-cloud-sdk-go/services/ecs"
 	"github.com/aliyun/alibaba-cloud-sdk-go/services/slb"
+	"github.com/gogap/config"
 	"github.com/sirupsen/logrus"
 )
 
@@ -167,3 +168,80 @@ func (p *Aliyun) CreateVServerGroup() (err error) {
 
 	return
 }
+
+// slbListenerServerGroups resolves the vserver group and master slave server group of the listener config,
+// the groups configured by name are looked up in the given balancer
+func (p *Aliyun) slbListenerServerGroups(lb *SLBLoadBalancer, protocol string, listenerConfig config.Configuration) (vGroupId, msGroupId string, err error) {
+
+	vGroupId = listenerConfig.GetString("vserver-group-id")
+
+	if vGroupName := listenerConfig.GetString("vserver-group-name"); len(vGroupId) == 0 && len(vGroupName) > 0 {
+
+		req := slb.CreateDescribeVServerGroupsRequest()
+
+		req.LoadBalancerId = lb.LoadBalancerId
+		req.RegionId = p.Region
+
+		var resp *slb.DescribeVServerGroupsResponse
+		resp, err = p.SLBClient().DescribeVServerGroups(req)
+		if err != nil {
+			return
+		}
+
+		for _, group := range resp.VServerGroups.VServerGroup {
+			if group.VServerGroupName == vGroupName {
+				vGroupId = group.VServerGroupId
+				break
+			}
+		}
+
+		if len(vGroupId) == 0 {
+			err = fmt.Errorf("vgroup of %s in lb %s not created", vGroupName, lb.LoadBalancerName)
+			return
+		}
+	}
+
+	msGroupId = listenerConfig.GetString("master-slave-server-group-id")
+
+	if msGroupName := listenerConfig.GetString("master-slave-server-group-name"); len(msGroupId) == 0 && len(msGroupName) > 0 {
+
+		req := slb.CreateDescribeMasterSlaveServerGroupsRequest()
+
+		req.LoadBalancerId = lb.LoadBalancerId
+		req.RegionId = p.Region
+
+		var resp *slb.DescribeMasterSlaveServerGroupsResponse
+		resp, err = p.SLBClient().DescribeMasterSlaveServerGroups(req)
+		if err != nil {
+			return
+		}
+
+		for _, group := range resp.MasterSlaveServerGroups.MasterSlaveServerGroup {
+			if group.MasterSlaveServerGroupName == msGroupName {
+				msGroupId = group.MasterSlaveServerGroupId
+				break
+			}
+		}
+
+		if len(msGroupId) == 0 {
+			err = fmt.Errorf("master slave server group of %s in lb %s not created", msGroupName, lb.LoadBalancerName)
+			return
+		}
+	}
+
+	if len(msGroupId) == 0 {
+		return
+	}
+
+	if protocol != "tcp" && protocol != "udp" {
+		err = fmt.Errorf("master slave server group is not supported by %s listener of lb %s", protocol, lb.LoadBalancerName)
+		return
+	}
+
+	if len(vGroupId) > 0 {
+		err = fmt.Errorf("vserver group and master slave server group could not be both used by listener of lb %s", lb.LoadBalancerName)
+		return
+	}
+
+	return
+}
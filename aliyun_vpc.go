@@ -1,9 +1,9 @@
 package aliyun
 
 import (
+	"context"
 	"fmt"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/aliyun/alibaba-cloud-sdk-go/services/vpc"
@@ -176,7 +176,7 @@ func (p *Aliyun) DeleteVPC() (err error) {
 	return
 }
 
-func (p *Aliyun) WaitForAllVpcRunning(timeout int) (err error) {
+func (p *Aliyun) WaitForAllVpcRunning(ctx context.Context, timeout int) (err error) {
 
 	vpcsConf := p.Config.GetConfig("aliyun.vpc.vpc")
 
@@ -213,20 +213,57 @@ func (p *Aliyun) WaitForAllVpcRunning(timeout int) (err error) {
 		return
 	}
 
-	wg := &sync.WaitGroup{}
+	var waits []func(ctx context.Context) error
 
-	wg.Add(len(vpcIds))
-
-	for i := 0; i < len(vpcIds); i++ {
-		go func(vpcId string) {
-			defer wg.Done()
-			p.WaitForVpcAvailable(vpcId, timeout)
-		}(vpcIds[i])
+	for _, vpcId := range vpcIds {
+		vpcId := vpcId
+		waits = append(waits, func(ctx context.Context) error {
+			return p.WaitForVpcAvailableContext(ctx, vpcId, timeout)
+		})
 	}
 
 	logrus.WithField("CODE", p.Code).Infoln("Wait for all VPC available")
 
-	wg.Wait()
+	err = p.waitAll(ctx, waits...)
+
+	return
+}
+
+func (p *Aliyun) WaitForAllVSwitchAvailable(ctx context.Context, timeout int) (err error) {
+
+	vSwitchesConf := p.Config.GetConfig("aliyun.vpc.vswitch")
+
+	if vSwitchesConf.IsEmpty() {
+		return
+	}
+
+	var waits []func(ctx context.Context) error
+
+	for _, vSwitchName := range vSwitchesConf.Keys() {
+
+		vpcName := vSwitchesConf.GetString(vSwitchName + ".vpc-name")
+
+		var vSwitch *vpc.VSwitch
+		vSwitch, err = p.FindVSwitch(vpcName, vSwitchName)
+		if err != nil {
+			return
+		}
+
+		if vSwitch == nil {
+			err = fmt.Errorf("vswitch %s of vpc %s not found", vSwitchName, vpcName)
+			return
+		}
+
+		vpcId, vSwitchId := vSwitch.VpcId, vSwitch.VSwitchId
+
+		waits = append(waits, func(ctx context.Context) error {
+			return p.WaitForVSwitchAvailableContext(ctx, vpcId, vSwitchId, timeout)
+		})
+	}
+
+	logrus.WithField("CODE", p.Code).Infoln("Wait for all VSwitch available")
+
+	err = p.waitAll(ctx, waits...)
 
 	return
 }
@@ -434,40 +471,47 @@ func (p *Aliyun) DeleteVSwitch() (err error) {
 }
 
 func (p *Aliyun) WaitForVpcAvailable(vpcId string, timeout int) (err error) {
+	return p.WaitForVpcAvailableContext(context.Background(), vpcId, timeout)
+}
+
+func (p *Aliyun) WaitForVpcAvailableContext(ctx context.Context, vpcId string, timeout int) (err error) {
 	if timeout <= 0 {
 		timeout = 60
 	}
 
-	for {
+	waiter := p.newWaiter(fmt.Sprintf("vpc '%s' available", vpcId), time.Duration(timeout)*time.Second, []string{"Available"})
+	waiter.Fields["VPC-ID"] = vpcId
+
+	return waiter.Wait(ctx, func() (status string, err error) {
 		var resp *vpc.DescribeVpcsResponse
 		resp, err = p.describeVPCs(vpcId)
 		if err != nil {
-			return err
-		}
-
-		if len(resp.Vpcs.Vpc) > 0 && resp.Vpcs.Vpc[0].Status == "Available" {
-			break
+			return
 		}
 
-		timeout = timeout - 5
-
-		if timeout <= 0 {
-			err = fmt.Errorf("wait for vpc '%s' available timeout", vpcId)
+		if len(resp.Vpcs.Vpc) == 0 {
+			status = WaitNotFound
 			return
 		}
 
-		time.Sleep(5 * time.Second)
-	}
-	return nil
+		status = resp.Vpcs.Vpc[0].Status
+		return
+	})
 }
 
 func (p *Aliyun) WaitForVSwitchAvailable(vpcId string, vswitchId string, timeout int) (err error) {
+	return p.WaitForVSwitchAvailableContext(context.Background(), vpcId, vswitchId, timeout)
+}
+
+func (p *Aliyun) WaitForVSwitchAvailableContext(ctx context.Context, vpcId string, vswitchId string, timeout int) (err error) {
 	if timeout <= 0 {
 		timeout = 60
 	}
 
-	for {
+	waiter := p.newWaiter(fmt.Sprintf("vswitch '%s' available", vswitchId), time.Duration(timeout)*time.Second, []string{"Available"})
+	waiter.Fields["VSWITCH-ID"] = vswitchId
 
+	return waiter.Wait(ctx, func() (status string, err error) {
 		var resp *vpc.DescribeVSwitchesResponse
 		resp, err = p.describeVSwitches(vpcId, vswitchId)
 		if err != nil {
@@ -475,21 +519,11 @@ func (p *Aliyun) WaitForVSwitchAvailable(vpcId string, vswitchId string, timeout
 		}
 
 		if len(resp.VSwitches.VSwitch) == 0 {
-			err = fmt.Errorf("vswitch %s not found", vswitchId)
+			status = WaitNotFound
 			return
 		}
 
-		if resp.VSwitches.VSwitch[0].Status == "Available" {
-			break
-		}
-
-		timeout = timeout - 5
-		if timeout <= 0 {
-			err = fmt.Errorf("wait for vsiwtch '%s' available timeout", vswitchId)
-			return
-		}
-
-		time.Sleep(5 * time.Second)
-	}
-	return nil
+		status = resp.VSwitches.VSwitch[0].Status
+		return
+	})
 }
@@ -1,7 +1,6 @@
 package aliyun
 
 import (
-	"strings"
 	"sync"
 
 	"github.com/denverdino/aliyungo/common"
@@ -97,60 +96,10 @@ func WaitForAllClusterDeleted(ctx context.Context, conf config.Configuration) (e
 func waitCSClusterStatusTo(ctx context.Context, conf config.Configuration, status cs.ClusterState, timeout int) (err error) {
 	aliyun := NewAliyun(ctx, conf)
 
-	clusters, err := aliyun.CSClient().DescribeClusters("")
+	waitCtx, cancel := waitContext(ctx)
+	defer cancel()
 
-	if err != nil {
-		return
-	}
-
-	wg := &sync.WaitGroup{}
-
-	errChan := make(chan error, 1)
-
-	for _, cluster := range clusters {
-		wg.Add(1)
-
-		go func(cluster cs.ClusterType) {
-
-			defer wg.Done()
-
-			logrus.WithField("CODE", aliyun.Code).
-				WithField("DOCKER-CLUSTER-ID", cluster.ClusterID).
-				WithField("DOCKER-CLUSTER-NAME", cluster.Name).Infof("Waiting for cluster status to %s", status)
-
-			e := aliyun.CSClient().WaitForClusterAsyn(cluster.ClusterID, status, timeout)
-
-			if e != nil {
-
-				if strings.Contains(e.Error(), "ErrorClusterNotFound") {
-					return
-				}
-
-				logrus.WithField("CODE", aliyun.Code).
-					WithError(e).
-					WithField("DOCKER-CLUSTER-ID", cluster.ClusterID).
-					WithField("DOCKER-CLUSTER-NAME", cluster.Name).Errorf("Wait for cluster status to %s failure", status)
-
-				select {
-				case errChan <- e:
-				default:
-				}
-				return
-			}
-
-			logrus.WithField("CODE", aliyun.Code).
-				WithField("DOCKER-CLUSTER-ID", cluster.ClusterID).
-				WithField("DOCKER-CLUSTER-NAME", cluster.Name).Infof("Cluster status is %s", status)
-
-		}(cluster)
-	}
-
-	wg.Wait()
-
-	select {
-	case err = <-errChan:
-	default:
-	}
+	err = aliyun.WaitForDockerClusters(waitCtx, status, timeout)
 
 	return
 }
@@ -197,9 +146,12 @@ func CreateDockerClusterProject(ctx context.Context, conf config.Configuration)
 		return
 	}
 
+	waitCtx, cancel := waitContext(ctx)
+	defer cancel()
+
 	for _, arg := range args {
 
-		err = arg.Wait()
+		err = arg.Wait(waitCtx)
 		if err != nil {
 			return
 		}
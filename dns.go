@@ -10,6 +10,9 @@ func init() {
 	flow.RegisterHandler("devops.aliyun.dns.domain.record.add", AddDomainRecord)
 	flow.RegisterHandler("devops.aliyun.dns.domain.record.update", UpdateDomainRecord)
 	flow.RegisterHandler("devops.aliyun.dns.domain.record.delete", DeleteDomainRecord)
+	flow.RegisterHandler("devops.aliyun.dns.zone.sync", SyncDNSZone)
+	flow.RegisterHandler("devops.aliyun.dns.zone.export", ExportDNSZone)
+	flow.RegisterHandler("devops.aliyun.dns.zone.import", ImportDNSZone)
 }
 
 func AddDomainRecord(ctx context.Context, conf config.Configuration) (err error) {
@@ -47,3 +50,39 @@ func DeleteDomainRecord(ctx context.Context, conf config.Configuration) (err err
 
 	return
 }
+
+func SyncDNSZone(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	err = aliyun.SyncDNSZone()
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func ExportDNSZone(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	err = aliyun.ExportDNSZones()
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func ImportDNSZone(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	err = aliyun.ImportDNSZones()
+	if err != nil {
+		return
+	}
+
+	return
+}
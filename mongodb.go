@@ -0,0 +1,78 @@
+package aliyun
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gogap/config"
+	"github.com/gogap/context"
+	"github.com/gogap/flow"
+)
+
+func init() {
+	flow.RegisterHandler("devops.aliyun.mongodb.instance.create", CreateMongoDBInstance)
+	flow.RegisterHandler("devops.aliyun.mongodb.instance.delete", DeleteMongoDBInstance)
+	flow.RegisterHandler("devops.aliyun.mongodb.instance.running.wait", WaitForAllMongoDBRunning)
+	flow.RegisterHandler("devops.aliyun.mongodb.instance.describe", DescribeMongoDBInstance)
+}
+
+func CreateMongoDBInstance(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	err = aliyun.CreateMongoDBInstances()
+
+	return
+}
+
+func DeleteMongoDBInstance(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	err = aliyun.DeleteMongoDBInstances()
+
+	return
+}
+
+func WaitForAllMongoDBRunning(ctx context.Context, conf config.Configuration) (err error) {
+	aliyun := NewAliyun(ctx, conf)
+
+	waitCtx, cancel := waitContext(ctx)
+	defer cancel()
+
+	err = aliyun.WaitForMongoDBInstances(waitCtx, "Running", 60*30)
+
+	return
+}
+
+func DescribeMongoDBInstance(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	insts, err := aliyun.DescribeMongoDBInstances()
+	if err != nil {
+		return
+	}
+
+	if len(insts) == 0 {
+		return
+	}
+
+	data, err := json.Marshal(insts)
+	if err != nil {
+		return
+	}
+
+	var tags []string
+
+	for _, inst := range insts {
+		tags = append(tags, inst.Name)
+		setENV(fmt.Sprintf("mongodb_%s_uri", inst.Name), inst.URI)
+	}
+
+	tags = append(tags, "aliyun", "mongodb", aliyun.Code)
+
+	flow.AppendOutput(ctx, flow.NameValue{Name: "ALIYUN_MONGODB_INSTANCES", Value: data, Tags: tags})
+
+	return
+}
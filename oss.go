@@ -1,6 +1,8 @@
 package aliyun
 
 import (
+	"encoding/json"
+
 	"github.com/gogap/config"
 	"github.com/gogap/context"
 	"github.com/gogap/flow"
@@ -9,6 +11,9 @@ import (
 func init() {
 	flow.RegisterHandler("devops.aliyun.oss.bucket.create", CreateOSSBucket)
 	flow.RegisterHandler("devops.aliyun.oss.bucket.delete", DeleteOSSBucket)
+	flow.RegisterHandler("devops.aliyun.oss.website.setup", SetupOSSWebsite)
+	flow.RegisterHandler("devops.aliyun.oss.bucket.replication.setup", SetupOSSReplication)
+	flow.RegisterHandler("devops.aliyun.oss.bucket.replication.progress", DescribeOSSReplicationProgress)
 }
 
 func CreateOSSBucket(ctx context.Context, conf config.Configuration) (err error) {
@@ -36,3 +41,52 @@ func DeleteOSSBucket(ctx context.Context, conf config.Configuration) (err error)
 
 	return
 }
+
+func SetupOSSWebsite(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	err = aliyun.SetupOSSWebsite()
+
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func SetupOSSReplication(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	err = aliyun.SetupOSSReplication()
+
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func DescribeOSSReplicationProgress(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	progresses, err := aliyun.DescribeOSSReplicationProgress()
+	if err != nil {
+		return
+	}
+
+	if len(progresses) == 0 {
+		return
+	}
+
+	data, err := json.Marshal(progresses)
+	if err != nil {
+		return
+	}
+
+	flow.AppendOutput(ctx, flow.NameValue{Name: "ALIYUN_OSS_REPLICATION_PROGRESS", Value: data, Tags: []string{"aliyun", "oss", "replication", aliyun.Code}})
+
+	return
+}
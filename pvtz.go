@@ -0,0 +1,62 @@
+package aliyun
+
+import (
+	"github.com/gogap/config"
+	"github.com/gogap/context"
+	"github.com/gogap/flow"
+)
+
+func init() {
+	flow.RegisterHandler("devops.aliyun.pvtz.zone.create", CreatePrivateZone)
+	flow.RegisterHandler("devops.aliyun.pvtz.zone.delete", DeletePrivateZone)
+	flow.RegisterHandler("devops.aliyun.pvtz.zone.vpc.bind", BindPrivateZoneVPC)
+	flow.RegisterHandler("devops.aliyun.pvtz.zone.record.sync", SyncPrivateZoneRecord)
+}
+
+func CreatePrivateZone(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	err = aliyun.CreatePrivateZones()
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func DeletePrivateZone(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	err = aliyun.DeletePrivateZones()
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func BindPrivateZoneVPC(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	err = aliyun.BindPrivateZoneVPCs()
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func SyncPrivateZoneRecord(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	err = aliyun.SyncPrivateZoneRecords()
+	if err != nil {
+		return
+	}
+
+	return
+}
@@ -3,12 +3,10 @@ package aliyun
 import (
 	"encoding/json"
 	"fmt"
-	"sync"
 
 	"github.com/gogap/config"
 	"github.com/gogap/context"
 	"github.com/gogap/flow"
-	"github.com/sirupsen/logrus"
 )
 
 func init() {
@@ -17,7 +15,23 @@ func init() {
 	flow.RegisterHandler("devops.aliyun.rds.instance.netinfo.describe", DescribeRDSInstanceNetInfo)
 	flow.RegisterHandler("devops.aliyun.rds.instance.delete", DeleteRDSInstance)
 	flow.RegisterHandler("devops.aliyun.rds.instance.running.wait", WaitForAllRDSRunning)
+	flow.RegisterHandler("devops.aliyun.rds.instance.deleted.wait", WaitForAllRDSDeleted)
 	flow.RegisterHandler("devops.aliyun.rds.instance.account.create", CreateRDSDbAccounts)
+	flow.RegisterHandler("devops.aliyun.rds.instance.database.create", CreateRDSDatabases)
+	flow.RegisterHandler("devops.aliyun.rds.instance.database.delete", DeleteRDSDatabases)
+	flow.RegisterHandler("devops.aliyun.rds.instance.whitelist.sync", SyncRDSWhitelist)
+	flow.RegisterHandler("devops.aliyun.rds.instance.account.rotate", RotateRDSAccountPasswords)
+	flow.RegisterHandler("devops.aliyun.rds.instance.account.revoke", RevokeRDSRemovedAccounts)
+	flow.RegisterHandler("devops.aliyun.rds.instance.backup.policy.modify", ModifyRDSBackupPolicy)
+	flow.RegisterHandler("devops.aliyun.rds.instance.backup.create", CreateRDSBackup)
+	flow.RegisterHandler("devops.aliyun.rds.instance.clone", CloneRDSInstance)
+	flow.RegisterHandler("devops.aliyun.rds.instance.modify", ModifyRDSInstance)
+	flow.RegisterHandler("devops.aliyun.rds.instance.read-only.create", CreateRDSReadOnlyInstance)
+	flow.RegisterHandler("devops.aliyun.rds.instance.read-only.delete", DeleteRDSReadOnlyInstance)
+	flow.RegisterHandler("devops.aliyun.rds.instance.parameters.sync", SyncRDSParameters)
+	flow.RegisterHandler("devops.aliyun.rds.instance.encryption.apply", ApplyRDSEncryption)
+	flow.RegisterHandler("devops.aliyun.rds.instance.encryption.describe", DescribeRDSEncryption)
+	flow.RegisterHandler("devops.aliyun.rds.instance.logs.export", ExportRDSLogs)
 	flow.RegisterHandler("devops.aliyun.rds.instance.conn.public.alloc", AllocateInstancePublicConnection)
 	flow.RegisterHandler("devops.aliyun.rds.instance.conn.public.release", ReleaseInstancePublicConnection)
 }
@@ -44,6 +58,241 @@ func CreateRDSDbAccounts(ctx context.Context, conf config.Configuration) (err er
 	return
 }
 
+func CreateRDSDatabases(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	err = aliyun.CreateRDSDatabases()
+
+	return
+}
+
+func DeleteRDSDatabases(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	err = aliyun.DeleteRDSDatabases()
+
+	return
+}
+
+func SyncRDSWhitelist(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	err = aliyun.SyncRDSWhitelist()
+
+	return
+}
+
+func RotateRDSAccountPasswords(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	// check the key before passwords reset, or else the new passwords will be lost
+	encryptKey := aliyun.outputEncryptKey()
+	if len(encryptKey) == 0 {
+		err = fmt.Errorf("the output encrypt key is empty, please set it to config of aliyun.output-encrypt-key or env ${ENV_ALIYUN_OUTPUT_ENCRYPT_KEY}")
+		return
+	}
+
+	// the passwords rotated before failure should be output too, or else they will be lost
+	credentials, rotateErr := aliyun.RotateRDSAccountPasswords()
+
+	if len(credentials) == 0 {
+		err = rotateErr
+		return
+	}
+
+	data, err := json.Marshal(credentials)
+	if err != nil {
+		return
+	}
+
+	encrypted, err := encryptOutput(encryptKey, data)
+	if err != nil {
+		return
+	}
+
+	var tags []string
+
+	for _, cred := range credentials {
+		tags = append(tags, cred.InstanceName)
+	}
+
+	tags = append(tags, "aliyun", "rds", "encrypted", aliyun.Code)
+
+	flow.AppendOutput(ctx, flow.NameValue{Name: "ALIYUN_RDS_ACCOUNT_CREDENTIALS", Value: encrypted, Tags: tags})
+
+	err = rotateErr
+
+	return
+}
+
+func RevokeRDSRemovedAccounts(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	err = aliyun.RevokeRDSRemovedAccounts()
+
+	return
+}
+
+func ModifyRDSBackupPolicy(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	err = aliyun.ModifyRDSBackupPolicy()
+
+	return
+}
+
+func CreateRDSBackup(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	backups, err := aliyun.CreateRDSBackups()
+	if err != nil {
+		return
+	}
+
+	if len(backups) == 0 {
+		return
+	}
+
+	data, err := json.Marshal(backups)
+	if err != nil {
+		return
+	}
+
+	var tags []string
+
+	for _, backup := range backups {
+		tags = append(tags, backup.InstanceName)
+		setENV(fmt.Sprintf("rds_db_%s_backup_id", backup.InstanceName), backup.BackupId)
+	}
+
+	tags = append(tags, "aliyun", "rds", "backup", aliyun.Code)
+
+	flow.AppendOutput(ctx, flow.NameValue{Name: "ALIYUN_RDS_BACKUPS", Value: data, Tags: tags})
+
+	return
+}
+
+func CloneRDSInstance(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	err = aliyun.CloneRDSInstances()
+
+	return
+}
+
+func ModifyRDSInstance(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	err = aliyun.ModifyRDSInstances()
+
+	return
+}
+
+func CreateRDSReadOnlyInstance(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	err = aliyun.CreateRDSReadOnlyInstances()
+
+	return
+}
+
+func DeleteRDSReadOnlyInstance(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	err = aliyun.DeleteRDSReadOnlyInstances()
+
+	return
+}
+
+func SyncRDSParameters(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	changes, err := aliyun.SyncRDSParameters()
+	if err != nil {
+		return
+	}
+
+	if len(changes) == 0 {
+		return
+	}
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return
+	}
+
+	flow.AppendOutput(ctx, flow.NameValue{Name: "ALIYUN_RDS_PARAMETER_CHANGES", Value: data, Tags: []string{"aliyun", "rds", "parameters", aliyun.Code}})
+
+	return
+}
+
+func ApplyRDSEncryption(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	err = aliyun.ApplyRDSEncryption()
+
+	return
+}
+
+func DescribeRDSEncryption(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	statuses, err := aliyun.DescribeRDSEncryption()
+	if err != nil {
+		return
+	}
+
+	if len(statuses) == 0 {
+		return
+	}
+
+	data, err := json.Marshal(statuses)
+	if err != nil {
+		return
+	}
+
+	flow.AppendOutput(ctx, flow.NameValue{Name: "ALIYUN_RDS_ENCRYPTION", Value: data, Tags: []string{"aliyun", "rds", "encryption", aliyun.Code}})
+
+	return
+}
+
+func ExportRDSLogs(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	summaries, err := aliyun.ExportRDSLogs()
+	if err != nil {
+		return
+	}
+
+	if len(summaries) == 0 {
+		return
+	}
+
+	data, err := json.Marshal(summaries)
+	if err != nil {
+		return
+	}
+
+	flow.AppendOutput(ctx, flow.NameValue{Name: "ALIYUN_RDS_SLOW_LOG_TOP", Value: data, Tags: []string{"aliyun", "rds", "logs", aliyun.Code}})
+
+	return
+}
+
 func DeleteRDSInstance(ctx context.Context, conf config.Configuration) (err error) {
 
 	aliyun := NewAliyun(ctx, conf)
@@ -57,7 +306,10 @@ func AllocateInstancePublicConnection(ctx context.Context, conf config.Configura
 
 	aliyun := NewAliyun(ctx, conf)
 
-	err = aliyun.AllocateInstancePublicConnection()
+	waitCtx, cancel := waitContext(ctx)
+	defer cancel()
+
+	err = aliyun.AllocateInstancePublicConnection(waitCtx)
 
 	return
 }
@@ -95,6 +347,12 @@ func DescribeRDSInstanceAttr(ctx context.Context, conf config.Configuration) (er
 		tags = append(tags, inst.Name)
 		setENV(fmt.Sprintf("rds_db_%s_host", inst.Name), inst.ConnectionString)
 		setENV(fmt.Sprintf("rds_db_%s_port", inst.Name), inst.Port)
+		setENV(fmt.Sprintf("rds_db_%s_engine", inst.Name), inst.Engine)
+
+		if profile, e := GetRDSEngineProfile(inst.Engine); e == nil {
+			setENV(fmt.Sprintf("rds_db_%s_url", inst.Name), profile.ConnectionURL(inst.ConnectionString, inst.Port, ""))
+			setENV(fmt.Sprintf("rds_db_%s_jdbc_url", inst.Name), profile.JDBCURL(inst.ConnectionString, inst.Port, ""))
+		}
 	}
 
 	tags = append(tags, "aliyun", "rds", aliyun.Code)
@@ -143,24 +401,21 @@ func DescribeRDSInstanceNetInfo(ctx context.Context, conf config.Configuration)
 func WaitForAllRDSRunning(ctx context.Context, conf config.Configuration) (err error) {
 	aliyun := NewAliyun(ctx, conf)
 
-	inst, err := aliyun.listRDSInstance(nil)
+	waitCtx, cancel := waitContext(ctx)
+	defer cancel()
 
-	if err != nil {
-		return
-	}
+	err = aliyun.WaitForDBInstances(waitCtx, "Running", 60*20)
 
-	wg := &sync.WaitGroup{}
+	return
+}
 
-	for _, v := range inst.Items.DBInstance {
-		wg.Add(1)
-		go func(instId, name string) {
-			defer wg.Done()
-			logrus.WithField("CODE", aliyun.Code).WithField("RDS-DBINSTANCE-ID", instId).WithField("RDS-DBINSTANCE-NAME", name).Infoln("Waiting db instance")
-			aliyun.WaitForDBInstance(instId, "Running", 60*20)
-		}(v.DBInstanceId, v.DBInstanceDescription)
-	}
+func WaitForAllRDSDeleted(ctx context.Context, conf config.Configuration) (err error) {
+	aliyun := NewAliyun(ctx, conf)
+
+	waitCtx, cancel := waitContext(ctx)
+	defer cancel()
 
-	wg.Wait()
+	err = aliyun.WaitForDBInstances(waitCtx, WaitNotFound, 60*20)
 
 	return
 }
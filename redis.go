@@ -0,0 +1,80 @@
+package aliyun
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/gogap/config"
+	"github.com/gogap/context"
+	"github.com/gogap/flow"
+)
+
+func init() {
+	flow.RegisterHandler("devops.aliyun.redis.instance.create", CreateRedisInstance)
+	flow.RegisterHandler("devops.aliyun.redis.instance.delete", DeleteRedisInstance)
+	flow.RegisterHandler("devops.aliyun.redis.instance.running.wait", WaitForAllRedisRunning)
+	flow.RegisterHandler("devops.aliyun.redis.instance.describe", DescribeRedisInstance)
+}
+
+func CreateRedisInstance(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	err = aliyun.CreateRedisInstances()
+
+	return
+}
+
+func DeleteRedisInstance(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	err = aliyun.DeleteRedisInstances()
+
+	return
+}
+
+func WaitForAllRedisRunning(ctx context.Context, conf config.Configuration) (err error) {
+	aliyun := NewAliyun(ctx, conf)
+
+	waitCtx, cancel := waitContext(ctx)
+	defer cancel()
+
+	err = aliyun.WaitForRedisInstances(waitCtx, "Normal", 60*20)
+
+	return
+}
+
+func DescribeRedisInstance(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	insts, err := aliyun.DescribeRedisInstances()
+	if err != nil {
+		return
+	}
+
+	if len(insts) == 0 {
+		return
+	}
+
+	data, err := json.Marshal(insts)
+	if err != nil {
+		return
+	}
+
+	var tags []string
+
+	for _, inst := range insts {
+		tags = append(tags, inst.Name)
+		setENV(fmt.Sprintf("redis_%s_host", inst.Name), inst.ConnectionDomain)
+		setENV(fmt.Sprintf("redis_%s_port", inst.Name), strconv.FormatInt(int64(inst.Port), 10))
+	}
+
+	tags = append(tags, "aliyun", "redis", aliyun.Code)
+
+	flow.AppendOutput(ctx, flow.NameValue{Name: "ALIYUN_REDIS_INSTANCES", Value: data, Tags: tags})
+
+	return
+}
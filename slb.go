@@ -13,10 +13,17 @@ func init() {
 	flow.RegisterHandler("devops.aliyun.slb.balancer.describe", DescribeSLBBalancers)
 	flow.RegisterHandler("devops.aliyun.slb.balancer.create", CreateSLBBalancer)
 	flow.RegisterHandler("devops.aliyun.slb.balancer.delete", DeleteSLBBalancer)
+	flow.RegisterHandler("devops.aliyun.slb.balancer.active.wait", WaitForAllSLBBalancerActive)
 	flow.RegisterHandler("devops.aliyun.slb.balancer.listener.http.create", CreateSLBHTTPBanlancerListener)
 	flow.RegisterHandler("devops.aliyun.slb.balancer.listener.https.create", CreateSLBHTTPSBanlancerListener)
 	flow.RegisterHandler("devops.aliyun.slb.balancer.listener.tcp.create", CreateSLBTCPBanlancerListener)
 	flow.RegisterHandler("devops.aliyun.slb.balancer.listener.udp.create", CreateSLBUDPBanlancerListener)
+	flow.RegisterHandler("devops.aliyun.slb.balancer.listener.http.update", UpdateSLBHTTPBanlancerListener)
+	flow.RegisterHandler("devops.aliyun.slb.balancer.listener.https.update", UpdateSLBHTTPSBanlancerListener)
+	flow.RegisterHandler("devops.aliyun.slb.balancer.listener.tcp.update", UpdateSLBTCPBanlancerListener)
+	flow.RegisterHandler("devops.aliyun.slb.balancer.listener.udp.update", UpdateSLBUDPBanlancerListener)
+	flow.RegisterHandler("devops.aliyun.slb.balancer.listener.stop", StopSLBBanlancerListener)
+	flow.RegisterHandler("devops.aliyun.slb.balancer.listener.delete", DeleteSLBBanlancerListener)
 	flow.RegisterHandler("devops.aliyun.slb.balancer.listener.vserver-group.create", CreateVServerGroup)
 	flow.RegisterHandler("devops.aliyun.slb.balancer.listener.rules.create", CreateSLBHTTPListenerRule)
 }
@@ -43,6 +50,17 @@ func DescribeSLBBalancers(ctx context.Context, conf config.Configuration) (err e
 	return
 }
 
+func WaitForAllSLBBalancerActive(ctx context.Context, conf config.Configuration) (err error) {
+	aliyun := NewAliyun(ctx, conf)
+
+	waitCtx, cancel := waitContext(ctx)
+	defer cancel()
+
+	err = aliyun.WaitForAllLoadBalancerActive(waitCtx, 60*5)
+
+	return
+}
+
 func CreateSLBBalancer(ctx context.Context, conf config.Configuration) (err error) {
 
 	aliyun := NewAliyun(ctx, conf)
@@ -115,6 +133,78 @@ func CreateSLBUDPBanlancerListener(ctx context.Context, conf config.Configuratio
 	return
 }
 
+func UpdateSLBHTTPBanlancerListener(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	err = aliyun.UpdateLoadBalancerHTTPListener()
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func UpdateSLBHTTPSBanlancerListener(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	err = aliyun.UpdateLoadBalancerHTTPSListener()
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func UpdateSLBTCPBanlancerListener(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	err = aliyun.UpdateLoadBalancerTCPListener()
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func UpdateSLBUDPBanlancerListener(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	err = aliyun.UpdateLoadBalancerUDPListener()
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func StopSLBBanlancerListener(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	err = aliyun.StopLoadBalancerListeners()
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func DeleteSLBBanlancerListener(ctx context.Context, conf config.Configuration) (err error) {
+
+	aliyun := NewAliyun(ctx, conf)
+
+	err = aliyun.DeleteLoadBalancerListeners()
+	if err != nil {
+		return
+	}
+
+	return
+}
+
 func CreateVServerGroup(ctx context.Context, conf config.Configuration) (err error) {
 	aliyun := NewAliyun(ctx, conf)
 
@@ -12,6 +12,7 @@ func init() {
 	flow.RegisterHandler("devops.aliyun.vpc.vpc.running.wait", WaitForAllVpcRunning)
 	flow.RegisterHandler("devops.aliyun.vpc.vswitch.create", CreateVSwitch)
 	flow.RegisterHandler("devops.aliyun.vpc.vswitch.delete", DeleteVSwitch)
+	flow.RegisterHandler("devops.aliyun.vpc.vswitch.available.wait", WaitForAllVSwitchAvailable)
 }
 
 func CreateVPC(ctx context.Context, conf config.Configuration) (err error) {
@@ -41,7 +42,10 @@ func DeleteVPC(ctx context.Context, conf config.Configuration) (err error) {
 func WaitForAllVpcRunning(ctx context.Context, conf config.Configuration) (err error) {
 	aliyun := NewAliyun(ctx, conf)
 
-	err = aliyun.WaitForAllVpcRunning(30)
+	waitCtx, cancel := waitContext(ctx)
+	defer cancel()
+
+	err = aliyun.WaitForAllVpcRunning(waitCtx, 30)
 
 	if err != nil {
 		return
@@ -72,3 +76,14 @@ func DeleteVSwitch(ctx context.Context, conf config.Configuration) (err error) {
 
 	return
 }
+
+func WaitForAllVSwitchAvailable(ctx context.Context, conf config.Configuration) (err error) {
+	aliyun := NewAliyun(ctx, conf)
+
+	waitCtx, cancel := waitContext(ctx)
+	defer cancel()
+
+	err = aliyun.WaitForAllVSwitchAvailable(waitCtx, 60)
+
+	return
+}
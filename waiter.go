@@ -0,0 +1,243 @@
+package aliyun
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// WaitNotFound is the status should be returned by WaitStatusFunc while the resource not exist, it is the ready
+// status of delete waits, the create or ready waits keep polling on it, the new resource may be not visible yet
+const WaitNotFound = "NotFound"
+
+type WaitStatusFunc func() (status string, err error)
+
+type Waiter struct {
+	Name string
+
+	// Ready is the status list of wait success
+	Ready []string
+	// Terminal is the status list which will never reach ready, the wait fails immediately
+	Terminal []string
+
+	Timeout  time.Duration
+	Interval time.Duration
+
+	// Backoff doubles the interval after each poll, until MaxInterval
+	Backoff     bool
+	MaxInterval time.Duration
+
+	// Fields are logged with the progress
+	Fields logrus.Fields
+}
+
+// newWaiter creates waiter with the options of aliyun.wait, the timeout in config overrides the default timeout
+func (p *Aliyun) newWaiter(name string, timeout time.Duration, ready []string, terminal ...string) *Waiter {
+
+	waitConf := p.Config.GetConfig("aliyun.wait")
+
+	if confTimeout := waitConf.GetInt32("timeout", 0); confTimeout > 0 {
+		timeout = time.Duration(confTimeout) * time.Second
+	}
+
+	return &Waiter{
+		Name:        name,
+		Ready:       ready,
+		Terminal:    terminal,
+		Timeout:     timeout,
+		Interval:    time.Duration(waitConf.GetInt32("interval", 5)) * time.Second,
+		Backoff:     waitConf.GetBoolean("backoff", false),
+		MaxInterval: time.Duration(waitConf.GetInt32("max-interval", 60)) * time.Second,
+		Fields:      logrus.Fields{"CODE": p.Code},
+	}
+}
+
+// waitAll runs the waits with max concurrency of aliyun.wait.concurrency
+func (p *Aliyun) waitAll(ctx context.Context, waits ...func(ctx context.Context) error) error {
+	return WaitAll(ctx, int(p.Config.GetInt32("aliyun.wait.concurrency", 0)), waits...)
+}
+
+// waitResources waits for the resources of ids concurrently, the names are in the same order of ids,
+// and prefix the errors of their waits, e.g. "redis 'cache': ..."
+func (p *Aliyun) waitResources(ctx context.Context, resource string, ids, names []string, wait func(ctx context.Context, id string) error) error {
+
+	var waits []func(ctx context.Context) error
+
+	for i := range ids {
+
+		id, name := ids[i], names[i]
+
+		waits = append(waits, func(ctx context.Context) error {
+			e := wait(ctx, id)
+			if e != nil {
+				return fmt.Errorf("%s '%s': %s", resource, name, e.Error())
+			}
+			return nil
+		})
+	}
+
+	return p.waitAll(ctx, waits...)
+}
+
+// terminalStatusExcept returns the terminal status without the status waiting for
+func terminalStatusExcept(terminal []string, status string) (ret []string) {
+	for _, s := range terminal {
+		if s != status {
+			ret = append(ret, s)
+		}
+	}
+	return
+}
+
+func (p *Waiter) Wait(ctx context.Context, statusFn WaitStatusFunc) (err error) {
+
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = 2 * time.Minute
+	}
+
+	interval := p.Interval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	maxInterval := p.MaxInterval
+	if maxInterval < interval {
+		maxInterval = interval
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	lastStatus := ""
+
+	for {
+		status, e := statusFn()
+		if e != nil {
+			err = fmt.Errorf("wait for %s failure: %s", p.Name, e.Error())
+			return
+		}
+
+		if status != lastStatus {
+			logrus.WithFields(p.Fields).
+				WithField("WAIT", p.Name).
+				WithField("STATUS", status).
+				WithField("ELAPSED", time.Since(start).Round(time.Second)).Infoln("Waiting status changed")
+		}
+
+		if stringInSlice(status, p.Ready) {
+			return
+		}
+
+		if stringInSlice(status, p.Terminal) {
+			err = fmt.Errorf("wait for %s failure, the status is %s", p.Name, status)
+			return
+		}
+
+		lastStatus = status
+
+		select {
+		case <-ctx.Done():
+			{
+				if ctx.Err() == context.DeadlineExceeded {
+					err = fmt.Errorf("wait for %s timeout after %s, the last status is %s", p.Name, timeout, lastStatus)
+				} else {
+					err = fmt.Errorf("wait for %s canceled, the last status is %s", p.Name, lastStatus)
+				}
+				return
+			}
+		case <-time.After(interval):
+		}
+
+		if p.Backoff {
+			interval = interval * 2
+			if interval > maxInterval {
+				interval = maxInterval
+			}
+		}
+	}
+}
+
+type WaitErrors []error
+
+func (p WaitErrors) Error() string {
+	var msgs []string
+	for _, err := range p {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// WaitAll runs the waits concurrently and aggregates all of the errors,
+// concurrency less than or equal to 0 means no limit
+func WaitAll(ctx context.Context, concurrency int, waits ...func(ctx context.Context) error) (err error) {
+
+	if concurrency <= 0 || concurrency > len(waits) {
+		concurrency = len(waits)
+	}
+
+	var errs WaitErrors
+
+	locker := sync.Mutex{}
+	wg := &sync.WaitGroup{}
+
+	tokens := make(chan struct{}, concurrency)
+
+	for _, wait := range waits {
+		wg.Add(1)
+		go func(wait func(ctx context.Context) error) {
+			defer wg.Done()
+
+			tokens <- struct{}{}
+			defer func() { <-tokens }()
+
+			e := wait(ctx)
+			if e == nil {
+				return
+			}
+
+			locker.Lock()
+			errs = append(errs, e)
+			locker.Unlock()
+		}(wait)
+	}
+
+	wg.Wait()
+
+	if len(errs) > 0 {
+		err = errs
+	}
+
+	return
+}
+
+// waitContext returns the context of the waits in handler, it is canceled with the flow context while
+// the flow context supports cancellation, the signals are left to the host flow runner
+func waitContext(flowCtx interface{}) (ctx context.Context, cancel context.CancelFunc) {
+
+	if parent, ok := flowCtx.(context.Context); ok {
+		return context.WithCancel(parent)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+
+	done, ok := flowCtx.(interface{ Done() <-chan struct{} })
+	if !ok {
+		return
+	}
+
+	go func() {
+		select {
+		case <-done.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return
+}